@@ -2,11 +2,14 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/scryner/util.slack/internal/lrucache"
@@ -20,11 +23,17 @@ const (
 
 type API struct {
 	serverAddr     string
-	botAccessToken string
+	tokenSource    TokenSource
 	requestTimeout time.Duration
 	cacheCapacity  int
 
 	httpCli          *http.Client
+	rateLimiter      *rateLimiter
+	retryPolicy      *RetryPolicy
+	interceptors     []Interceptor
+	dryRun           *DryRunConfig
+	validateToken    bool
+	requiredScopes   []string
 	emailToUserCache Cache
 	idToUserCache    Cache
 }
@@ -66,11 +75,44 @@ func CacheCapacity(capacity int) Option {
 	}
 }
 
+func RateLimit(config RateLimitConfig) Option {
+	return func(api *API) error {
+		api.rateLimiter = newRateLimiter(config)
+		return nil
+	}
+}
+
+// HTTPClient makes the client send requests by client (e.g., for a proxy,
+// mTLS or a test double); RequestTimeout is ignored then
+func HTTPClient(client *http.Client) Option {
+	return func(api *API) error {
+		api.httpCli = client
+		return nil
+	}
+}
+
+func Retry(policy RetryPolicy) Option {
+	return func(api *API) error {
+		api.retryPolicy = policy.withDefaults()
+		return nil
+	}
+}
+
+// BotTokenSource makes the client get its token from src instead of the
+// token given to New (e.g., a RotatingTokenSource)
+func BotTokenSource(src TokenSource) Option {
+	return func(api *API) error {
+		api.tokenSource = src
+		return nil
+	}
+}
+
 func New(botAccessToken string, opts ...Option) (*API, error) {
 	api := &API{
 		serverAddr:     defaultServerAddr,
-		botAccessToken: botAccessToken,
+		tokenSource:    StaticToken(botAccessToken),
 		requestTimeout: defaultRequestTimeout,
+		rateLimiter:    newRateLimiter(defaultRateLimitConfig),
 	}
 
 	var err error
@@ -81,8 +123,10 @@ func New(botAccessToken string, opts ...Option) (*API, error) {
 		}
 	}
 
-	api.httpCli = &http.Client{
-		Timeout: api.requestTimeout,
+	if api.httpCli == nil {
+		api.httpCli = &http.Client{
+			Timeout: api.requestTimeout,
+		}
 	}
 
 	if api.emailToUserCache == nil {
@@ -93,73 +137,262 @@ func New(botAccessToken string, opts ...Option) (*API, error) {
 		api.idToUserCache = lrucache.NewCache(defaultLruCacheCapacity)
 	}
 
+	if api.dryRun != nil {
+		api.interceptors = append(api.interceptors, api.dryRun.intercept)
+	}
+
+	// auth.test is answered by nothing in dry-run without pass-through
+	if api.validateToken && !api.dryRun.answers("auth.test") {
+		ctx, cancel := context.WithTimeout(context.Background(), api.requestTimeout)
+		defer cancel()
+
+		if err = api.validate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	return api, nil
 }
 
-func (api *API) doHTTPGet(apiPath string, params url.Values) (*http.Response, error) {
-	u := fmt.Sprintf("%s/%s?%s", api.serverAddr, apiPath, params.Encode())
-
-	req, err := http.NewRequest(http.MethodGet, u, nil)
+func (api *API) authorize(ctx context.Context, req *http.Request) error {
+	token, err := api.tokenSource.Token(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make http request: %v", err)
+		return fmt.Errorf("failed to get access token: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.botAccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return nil
+}
 
-	return api.httpCli.Do(req)
+func apiMethod(apiPath string) string {
+	return strings.TrimPrefix(apiPath, "api/")
 }
 
-func (api *API) doHTTPPost(apiPath string, params url.Values) (*http.Response, error) {
-	u := fmt.Sprintf("%s/%s?%s", api.serverAddr, apiPath, params.Encode())
+// cloneValues copies params so that interceptors changing parameters of a
+// call do not touch the caller's
+func cloneValues(params url.Values) url.Values {
+	if params == nil {
+		return nil
+	}
 
-	req, err := http.NewRequest(http.MethodPost, u, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make http request: %v", err)
+	cloned := make(url.Values, len(params))
+	for k, v := range params {
+		cloned[k] = append([]string(nil), v...)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.botAccessToken))
+	return cloned
+}
+
+// apiURL returns the URL of the method with params as the query if any
+func (api *API) apiURL(method string, params url.Values) string {
+	if len(params) == 0 {
+		return fmt.Sprintf("%s/api/%s", api.serverAddr, method)
+	}
 
-	return api.httpCli.Do(req)
+	return fmt.Sprintf("%s/api/%s?%s", api.serverAddr, method, params.Encode())
 }
 
-func (api *API) doHTTPPostJSON(apiPath string, params url.Values, v interface{}) (*http.Response, error) {
-	// marshal content
-	content, err := json.Marshal(v)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal content to JSON: %v", err)
+func (api *API) doHTTPGet(ctx context.Context, apiPath string, params url.Values) (*http.Response, error) {
+	call := &Call{
+		Method: apiMethod(apiPath),
+		Params: cloneValues(params),
 	}
 
-	var u string
-	if params == nil {
-		u = fmt.Sprintf("%s/%s", api.serverAddr, apiPath)
-	} else {
-		u = fmt.Sprintf("%s/%s?%s", api.serverAddr, apiPath, params.Encode())
+	return api.roundTrip(ctx, call, func(call *Call) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.apiURL(call.Method, call.Params), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make http request: %v", err)
+		}
+
+		if err = api.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	})
+}
+
+func (api *API) doHTTPPost(ctx context.Context, apiPath string, params url.Values) (*http.Response, error) {
+	call := &Call{
+		Method:  apiMethod(apiPath),
+		Params:  cloneValues(params),
+		channel: params.Get("channel"),
 	}
 
-	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make http request: %v", err)
+	return api.roundTrip(ctx, call, func(call *Call) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.apiURL(call.Method, call.Params), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make http request: %v", err)
+		}
+
+		if err = api.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	})
+}
+
+// doHTTPPostForm sends params as form body authorized by HTTP basic auth of
+// the client id and secret, which is for oauth.* methods
+func (api *API) doHTTPPostForm(ctx context.Context, apiPath string, params url.Values, clientId, clientSecret string) (*http.Response, error) {
+	call := &Call{
+		Method: apiMethod(apiPath),
+		Params: cloneValues(params),
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.botAccessToken))
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return api.roundTrip(ctx, call, func(call *Call) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.apiURL(call.Method, nil), strings.NewReader(call.Params.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to make http request: %v", err)
+		}
+
+		req.SetBasicAuth(clientId, clientSecret)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return req, nil
+	})
+}
+
+// channelScoped is implemented by request bodies whose rate limit is
+// counted per channel (e.g., chat.postMessage)
+type channelScoped interface {
+	channel() string
+}
+
+func (api *API) doHTTPPostJSON(ctx context.Context, apiPath string, params url.Values, v interface{}) (*http.Response, error) {
+	call := &Call{
+		Method: apiMethod(apiPath),
+		Params: cloneValues(params),
+		Body:   v,
+	}
+
+	if cs, ok := v.(channelScoped); ok {
+		call.channel = cs.channel()
+	}
+
+	return api.roundTrip(ctx, call, func(call *Call) (*http.Request, error) {
+		// marshal content
+		content, err := json.Marshal(call.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal content to JSON: %v", err)
+		}
 
-	return api.httpCli.Do(req)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.apiURL(call.Method, call.Params), bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to make http request: %v", err)
+		}
+
+		if err = api.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		return req, nil
+	})
+}
+
+func (api *API) get(ctx context.Context, method string, params url.Values, v response) error {
+	return api.call(ctx, method, v, func() (*http.Response, error) {
+		return api.doHTTPGet(ctx, "api/"+method, params)
+	})
+}
+
+func (api *API) post(ctx context.Context, method string, params url.Values, v response) error {
+	return api.call(ctx, method, v, func() (*http.Response, error) {
+		return api.doHTTPPost(ctx, "api/"+method, params)
+	})
+}
+
+func (api *API) postJSON(ctx context.Context, method string, body interface{}, v response) error {
+	return api.call(ctx, method, v, func() (*http.Response, error) {
+		return api.doHTTPPostJSON(ctx, "api/"+method, nil, body)
+	})
+}
+
+// call sends a request by send and decodes its response into v, retrying it
+// according to the retry policy
+func (api *API) call(ctx context.Context, method string, v response, send func() (*http.Response, error)) error {
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		if err != nil {
+			err = fmt.Errorf("failed to send request to %s: %w", method, err)
+		} else {
+			err = decodeResponse(method, resp, v)
+		}
+
+		delay, retry := api.retryPolicy.next(ctx, method, attempt, err)
+		if !retry {
+			return err
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// roundTrip sends the call through interceptors, where newReq builds the
+// request from the call as the interceptors left it, waiting for the rate
+// limiter beforehand and retrying it as long as Slack answers with 429
+func (api *API) roundTrip(ctx context.Context, call *Call, newReq func(call *Call) (*http.Request, error)) (*http.Response, error) {
+	method, channel := call.Method, call.channel
+
+	for retries := 0; ; retries++ {
+		// calls answered by dry-run do not reach Slack
+		if !api.dryRun.answers(method) {
+			if err := api.rateLimiter.wait(ctx, method, channel); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := api.invoke(ctx, call, newReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		// rate limited
+		retryAfter := parseRetryAfter(resp.Header)
+		if !api.rateLimiter.limited(method, channel, retryAfter, retries) {
+			// give up; let the caller see 429
+			return resp, nil
+		}
+
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
 }
 
 func (api *API) PrivateDownload(url string) (io.ReadCloser, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	return api.PrivateDownloadContext(context.Background(), url)
+}
+
+func (api *API) PrivateDownloadContext(ctx context.Context, url string) (io.ReadCloser, error) {
+	if api.dryRun != nil && !api.dryRun.PassThroughReads {
+		return ioutil.NopCloser(strings.NewReader("")), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %v", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", api.botAccessToken))
+	if err = api.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+
 	resp, err := api.httpCli.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to do private download to '%s': %v", url, err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("failed to do private download to '%s': %d(%s)", url, resp.StatusCode, resp.Status)
 	}
 
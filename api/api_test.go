@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPClient(t *testing.T) {
+	var urls []string
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       ioutil.NopCloser(strings.NewReader(`{"ok":true,"channel":"C1","ts":"1.0"}`)),
+				Request:    req,
+			}, nil
+		}),
+	}
+
+	slack, err := New("xoxb-test", ServerAddress("http://slack.test"), HTTPClient(client))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if _, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err != nil {
+		t.Fatal("failed to post message:", err)
+	}
+
+	body, err := slack.PrivateDownload("http://files.slack.test/F1")
+	if err != nil {
+		t.Fatal("failed to download:", err)
+	}
+
+	body.Close()
+
+	if len(urls) != 2 || urls[0] != "http://slack.test/api/chat.postMessage" || urls[1] != "http://files.slack.test/F1" {
+		t.Errorf("requests must be sent by the client: %v", urls)
+	}
+}
+
+func TestContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+
+	_, err = slack.PostMessageContext(ctx, "C1", &ChatMessage{Text: "hello"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("call must fail by the deadline, but %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("call must be aborted at the deadline: elapsed %v", elapsed)
+	}
+}
+
+func TestContextInterruptsWait(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/chat.postMessage") {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"ok":false,"error":"internal_error"}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), RateLimit(RateLimitConfig{}), Retry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   30 * time.Second,
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	// Retry-After of the first call blocks the bucket of the channel
+	if _, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err == nil {
+		t.Fatal("rate limited call must fail")
+	}
+
+	waits := map[string]func(ctx context.Context) error{
+		"rate limiter": func(ctx context.Context) error {
+			_, err := slack.PostMessageContext(ctx, "C1", &ChatMessage{Text: "hello"})
+			return err
+		},
+		"retry backoff": func(ctx context.Context) error {
+			return slack.DeleteMessageContext(ctx, "C1", "1.0")
+		},
+	}
+
+	for name, call := range waits {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		started := time.Now()
+
+		err := call(ctx)
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: call must fail by the deadline, but %v", name, err)
+		}
+
+		if elapsed := time.Since(started); elapsed > time.Second {
+			t.Errorf("%s: wait must be interrupted at the deadline: elapsed %v", name, elapsed)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AuthIdentity is the identity of the token given by auth.test
+type AuthIdentity struct {
+	URL                 string   `json:"url"`
+	Team                string   `json:"team"`
+	User                string   `json:"user"`
+	TeamID              string   `json:"team_id"`
+	UserID              string   `json:"user_id"` // bot user id for bot tokens
+	BotID               string   `json:"bot_id"`
+	EnterpriseID        string   `json:"enterprise_id"`
+	IsEnterpriseInstall bool     `json:"is_enterprise_install"`
+	Scopes              []string `json:"-"` // from x-oauth-scopes header
+}
+
+// MissingScopes returns scopes of required which are not granted
+func (identity *AuthIdentity) MissingScopes(required ...string) []string {
+	granted := make(map[string]bool, len(identity.Scopes))
+	for _, scope := range identity.Scopes {
+		granted[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+
+	return missing
+}
+
+type authTestResponse struct {
+	AuthIdentity
+	genericResponse
+}
+
+func (resp *authTestResponse) receiveHeader(header http.Header) {
+	for _, scope := range strings.Split(header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			resp.Scopes = append(resp.Scopes, scope)
+		}
+	}
+}
+
+func (api *API) AuthTest() (*AuthIdentity, error) {
+	return api.AuthTestContext(context.Background())
+}
+
+func (api *API) AuthTestContext(ctx context.Context) (*AuthIdentity, error) {
+	var resp authTestResponse
+
+	err := api.post(ctx, "auth.test", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.AuthIdentity, nil
+}
+
+// ValidateToken makes New check the token by auth.test and fail unless all of
+// requiredScopes are granted
+func ValidateToken(requiredScopes ...string) Option {
+	return func(api *API) error {
+		api.validateToken = true
+		api.requiredScopes = requiredScopes
+		return nil
+	}
+}
+
+func (api *API) validate(ctx context.Context) error {
+	identity, err := api.AuthTestContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to validate token: %w", err)
+	}
+
+	if missing := identity.MissingScopes(api.requiredScopes...); len(missing) > 0 {
+		return fmt.Errorf("failed to validate token: missing scopes %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+type TeamIcon struct {
+	Image34      string `json:"image_34"`
+	Image44      string `json:"image_44"`
+	Image68      string `json:"image_68"`
+	Image88      string `json:"image_88"`
+	Image102     string `json:"image_102"`
+	Image132     string `json:"image_132"`
+	Image230     string `json:"image_230"`
+	ImageDefault bool   `json:"image_default"`
+}
+
+type Team struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Domain         string   `json:"domain"`
+	EmailDomain    string   `json:"email_domain"`
+	Icon           TeamIcon `json:"icon"`
+	EnterpriseID   string   `json:"enterprise_id"`
+	EnterpriseName string   `json:"enterprise_name"`
+}
+
+type teamInfoResponse struct {
+	Team Team `json:"team"`
+	genericResponse
+}
+
+// GetTeamInfo returns the team; empty teamId means the team of the token
+func (api *API) GetTeamInfo(teamId string) (*Team, error) {
+	return api.GetTeamInfoContext(context.Background(), teamId)
+}
+
+func (api *API) GetTeamInfoContext(ctx context.Context, teamId string) (*Team, error) {
+	params := make(url.Values)
+	if teamId != "" {
+		params.Set("team", teamId)
+	}
+
+	var resp teamInfoResponse
+
+	err := api.get(ctx, "team.info", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Team, nil
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+			return
+		}
+
+		w.Header().Set("X-OAuth-Scopes", "chat:write, users:read")
+		w.Write([]byte(`{"ok":true,"url":"https://team.slack.com/","team":"team","user":"bot","team_id":"T1","user_id":"U1","bot_id":"B1"}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), ValidateToken("chat:write"))
+	if err != nil {
+		t.Fatal("token must be valid:", err)
+	}
+
+	identity, err := slack.AuthTest()
+	if err != nil {
+		t.Fatal("failed to auth.test:", err)
+	}
+
+	if identity.BotID != "B1" || len(identity.Scopes) != 2 || identity.Scopes[1] != "users:read" {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+
+	if _, err = New("xoxb-test", ServerAddress(ts.URL), ValidateToken("chat:write", "files:write")); err == nil {
+		t.Error("missing scope must fail")
+	}
+
+	if _, err = New("xoxb-wrong", ServerAddress(ts.URL), ValidateToken()); err == nil {
+		t.Error("invalid token must fail")
+	}
+}
+
+func TestGetTeamInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/team.info" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		switch team := r.URL.Query().Get("team"); team {
+		case "":
+			// the team of the token
+			w.Write([]byte(`{"ok":true,"team":{"id":"T1","name":"team","domain":"team","email_domain":"example.com","icon":{"image_68":"https://example.com/68.png"},"enterprise_id":"E1","enterprise_name":"enterprise"}}`))
+
+		case "T2":
+			w.Write([]byte(`{"ok":true,"team":{"id":"T2","name":"other","domain":"other"}}`))
+
+		default:
+			w.Write([]byte(`{"ok":false,"error":"team_not_found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	team, err := slack.GetTeamInfo("")
+	if err != nil {
+		t.Fatal("failed to get team info:", err)
+	}
+
+	if team.ID != "T1" || team.EmailDomain != "example.com" || team.Icon.Image68 == "" || team.EnterpriseID != "E1" || team.EnterpriseName != "enterprise" {
+		t.Errorf("unexpected team: %+v", team)
+	}
+
+	if team, err = slack.GetTeamInfo("T2"); err != nil || team.ID != "T2" || team.Domain != "other" {
+		t.Errorf("unexpected team: %+v, %v", team, err)
+	}
+
+	if _, err = slack.GetTeamInfo("T3"); err == nil {
+		t.Error("unknown team must fail")
+	}
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type Bookmark struct {
+	ID                  string `json:"id"`
+	ChannelID           string `json:"channel_id"`
+	Title               string `json:"title"`
+	Link                string `json:"link"`
+	Emoji               string `json:"emoji"`
+	IconURL             string `json:"icon_url"`
+	Type                string `json:"type"`
+	EntityID            string `json:"entity_id"`
+	Rank                string `json:"rank"`
+	DateCreated         int64  `json:"date_created"`
+	DateUpdated         int64  `json:"date_updated"`
+	LastUpdatedByUserID string `json:"last_updated_by_user_id"`
+	LastUpdatedByTeamID string `json:"last_updated_by_team_id"`
+	ShortcutID          string `json:"shortcut_id"`
+	AppID               string `json:"app_id"`
+}
+
+type BookmarkParams struct {
+	Title string
+	Link  string
+	Emoji string
+}
+
+type addBookmarkRequest struct {
+	ChannelId string `json:"channel_id"`
+	Title     string `json:"title"`
+	Type      string `json:"type"`
+	Link      string `json:"link,omitempty"`
+	Emoji     string `json:"emoji,omitempty"`
+}
+
+type bookmarkResponse struct {
+	Bookmark Bookmark `json:"bookmark"`
+	genericResponse
+}
+
+// AddBookmark adds a link bookmark to the channel
+func (api *API) AddBookmark(channelId string, params *BookmarkParams) (*Bookmark, error) {
+	return api.AddBookmarkContext(context.Background(), channelId, params)
+}
+
+func (api *API) AddBookmarkContext(ctx context.Context, channelId string, params *BookmarkParams) (*Bookmark, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty bookmark params")
+	}
+
+	var resp bookmarkResponse
+
+	err := api.postJSON(ctx, "bookmarks.add", addBookmarkRequest{
+		ChannelId: channelId,
+		Title:     params.Title,
+		Type:      "link",
+		Link:      params.Link,
+		Emoji:     params.Emoji,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Bookmark, nil
+}
+
+type editBookmarkRequest struct {
+	ChannelId  string `json:"channel_id"`
+	BookmarkId string `json:"bookmark_id"`
+	Title      string `json:"title,omitempty"`
+	Link       string `json:"link,omitempty"`
+	Emoji      string `json:"emoji,omitempty"`
+}
+
+// EditBookmark updates non-empty fields of params
+func (api *API) EditBookmark(channelId, bookmarkId string, params *BookmarkParams) (*Bookmark, error) {
+	return api.EditBookmarkContext(context.Background(), channelId, bookmarkId, params)
+}
+
+func (api *API) EditBookmarkContext(ctx context.Context, channelId, bookmarkId string, params *BookmarkParams) (*Bookmark, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty bookmark params")
+	}
+
+	var resp bookmarkResponse
+
+	err := api.postJSON(ctx, "bookmarks.edit", editBookmarkRequest{
+		ChannelId:  channelId,
+		BookmarkId: bookmarkId,
+		Title:      params.Title,
+		Link:       params.Link,
+		Emoji:      params.Emoji,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Bookmark, nil
+}
+
+type removeBookmarkRequest struct {
+	ChannelId  string `json:"channel_id"`
+	BookmarkId string `json:"bookmark_id"`
+}
+
+func (api *API) RemoveBookmark(channelId, bookmarkId string) error {
+	return api.RemoveBookmarkContext(context.Background(), channelId, bookmarkId)
+}
+
+func (api *API) RemoveBookmarkContext(ctx context.Context, channelId, bookmarkId string) error {
+	return api.postJSON(ctx, "bookmarks.remove", removeBookmarkRequest{
+		ChannelId:  channelId,
+		BookmarkId: bookmarkId,
+	}, new(genericResponse))
+}
+
+type listBookmarksResponse struct {
+	Bookmarks []Bookmark `json:"bookmarks"`
+	genericResponse
+}
+
+func (api *API) ListBookmarks(channelId string) ([]Bookmark, error) {
+	return api.ListBookmarksContext(context.Background(), channelId)
+}
+
+func (api *API) ListBookmarksContext(ctx context.Context, channelId string) ([]Bookmark, error) {
+	params := make(url.Values)
+	params.Set("channel_id", channelId)
+
+	var resp listBookmarksResponse
+
+	err := api.post(ctx, "bookmarks.list", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Bookmarks, nil
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	var req map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/bookmarks.add", "/api/bookmarks.edit", "/api/bookmarks.remove":
+			req = nil
+			json.NewDecoder(r.Body).Decode(&req)
+
+			w.Write([]byte(`{"ok":true,"bookmark":{"id":"Bk1","channel_id":"C1","title":"Docs","link":"https://example.com","type":"link"}}`))
+
+		case "/api/bookmarks.list":
+			if r.URL.Query().Get("channel_id") != "C1" {
+				t.Errorf("unexpected query: %v", r.URL.Query())
+			}
+
+			w.Write([]byte(`{"ok":true,"bookmarks":[{"id":"Bk1","channel_id":"C1","title":"Docs"}]}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	bookmark, err := slack.AddBookmark("C1", &BookmarkParams{Title: "Docs", Link: "https://example.com"})
+	if err != nil {
+		t.Fatal("failed to add bookmark:", err)
+	}
+
+	if req["channel_id"] != "C1" || req["title"] != "Docs" || req["type"] != "link" || req["link"] != "https://example.com" {
+		t.Errorf("unexpected add request: %v", req)
+	}
+
+	if _, ok := req["emoji"]; ok {
+		t.Errorf("empty emoji must be omitted: %v", req)
+	}
+
+	if bookmark.ID != "Bk1" || bookmark.Link != "https://example.com" {
+		t.Errorf("unexpected bookmark: %+v", bookmark)
+	}
+
+	if _, err = slack.EditBookmark("C1", "Bk1", &BookmarkParams{Emoji: ":books:"}); err != nil {
+		t.Fatal("failed to edit bookmark:", err)
+	}
+
+	// only non-empty fields are updated
+	if req["bookmark_id"] != "Bk1" || req["emoji"] != ":books:" || req["title"] != nil || req["link"] != nil {
+		t.Errorf("unexpected edit request: %v", req)
+	}
+
+	bookmarks, err := slack.ListBookmarks("C1")
+	if err != nil || len(bookmarks) != 1 || bookmarks[0].Title != "Docs" {
+		t.Errorf("unexpected bookmarks: %v, %+v", err, bookmarks)
+	}
+
+	if err = slack.RemoveBookmark("C1", "Bk1"); err != nil {
+		t.Fatal("failed to remove bookmark:", err)
+	}
+
+	if req["channel_id"] != "C1" || req["bookmark_id"] != "Bk1" {
+		t.Errorf("unexpected remove request: %v", req)
+	}
+
+	// nil params are rejected instead of panicking
+	if _, err = slack.AddBookmark("C1", nil); err == nil {
+		t.Error("nil params must be rejected by AddBookmark")
+	}
+
+	if _, err = slack.EditBookmark("C1", "Bk1", nil); err == nil {
+		t.Error("nil params must be rejected by EditBookmark")
+	}
+}
@@ -3,4 +3,4 @@ package api
 type Cache interface {
 	Set(key string, data interface{}) error
 	Get(key string) (interface{}, bool, error)
-}
\ No newline at end of file
+}
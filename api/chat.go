@@ -1,66 +1,26 @@
 package api
 
 import (
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/scryner/util.slack/block"
 )
 
-type openDMChannelResponse struct {
-	OK      bool `json:"ok"`
-	Channel struct {
-		ID string `json:"id"`
-	} `json:"channel"`
-	Error string `json:"error"`
-}
-
-func (api *API) openDMChannel(user *User) (string, error) {
+func (api *API) openDMChannel(ctx context.Context, user *User) (string, error) {
 	if user.DMChannel != "" {
 		return user.DMChannel, nil
 	}
 
 	// fallback
-	params := make(url.Values)
-	params.Set("users", user.ID)
-
-	resp, err := api.doHTTPPost("api/conversations.open", params)
+	conversation, err := api.OpenConversationContext(ctx, user.ID)
 	if err != nil {
-		return "", fmt.Errorf("failed to request open dm channel: %v", err)
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to request open dm channel: status = %s", resp.Status)
+		return "", err
 	}
 
-	// try to parse result
-	var openDMChannelResp openDMChannelResponse
-
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	err = json.Unmarshal(b, &openDMChannelResp)
-	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
-
-	if !openDMChannelResp.OK {
-		return "", fmt.Errorf("failed to open dm channel: %s", openDMChannelResp.Error)
-	}
-
-	channelID := openDMChannelResp.Channel.ID
-
-	if channelID == "" {
-		return "", fmt.Errorf("empty channel id")
-	}
+	channelID := conversation.ID
 
 	// set to cache
 	user.DMChannel = channelID
@@ -75,6 +35,26 @@ type ChatMessage struct {
 	Blocks      []block.Block      `json:"blocks,omitempty"`
 	Attachments []block.Attachment `json:"attachments,omitempty"`
 	ThreadTs    string             `json:"thread_ts,omitempty"`
+
+	ReplyBroadcast bool   `json:"reply_broadcast,omitempty"`
+	UnfurlLinks    *bool  `json:"unfurl_links,omitempty"`
+	UnfurlMedia    *bool  `json:"unfurl_media,omitempty"`
+	Mrkdwn         *bool  `json:"mrkdwn,omitempty"`
+	Parse          string `json:"parse,omitempty"` // "full" or "none"
+	LinkNames      bool   `json:"link_names,omitempty"`
+
+	// customize bot identity; chat:write.customize scope is needed
+	Username  string `json:"username,omitempty"`
+	IconEmoji string `json:"icon_emoji,omitempty"`
+	IconURL   string `json:"icon_url,omitempty"`
+
+	Metadata *MessageMetadata `json:"metadata,omitempty"`
+}
+
+// MessageMetadata is structured data attached to a message
+type MessageMetadata struct {
+	EventType    string                 `json:"event_type"`
+	EventPayload map[string]interface{} `json:"event_payload"`
 }
 
 type postChatMessageRequest struct {
@@ -82,70 +62,64 @@ type postChatMessageRequest struct {
 	*ChatMessage
 }
 
+func (req postChatMessageRequest) channel() string {
+	return req.ChannelId
+}
+
 type postMessageResponse struct {
-	ChannelId string `json:"channel"`
-	Timestamp string `json:"ts"`
+	ChannelId string  `json:"channel"`
+	Timestamp string  `json:"ts"`
+	Message   Message `json:"message"`
 	genericResponse
 }
 
-var (
-	ErrUserNotFound = errors.New("failed to search user")
-)
-
 func (api *API) PostBotDirectMessage(user *User, msg *ChatMessage) (channelId, ts string, err error) {
+	return api.PostBotDirectMessageContext(context.Background(), user, msg)
+}
+
+func (api *API) PostBotDirectMessageContext(ctx context.Context, user *User, msg *ChatMessage) (channelId, ts string, err error) {
 	// open DM channel
-	channelId, err = api.openDMChannel(user)
+	channelId, err = api.openDMChannel(ctx, user)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to open DM channel for '%s': %v", user.Profile.Email, err)
+		return "", "", fmt.Errorf("failed to open DM channel for '%s': %w", user.Profile.Email, err)
 	}
 
 	// post message
-	ts, err = api.PostMessage(channelId, msg)
+	posted, err := api.PostMessageContext(ctx, channelId, msg)
 	if err != nil {
 		return "", "", err
 	}
 
-	return
+	return posted.Channel, posted.Ts, nil
+}
+
+// PostMessage posts msg to the channel and returns the posted message
+func (api *API) PostMessage(channelId string, msg *ChatMessage) (*Message, error) {
+	return api.PostMessageContext(context.Background(), channelId, msg)
 }
 
-func (api *API) PostMessage(channelId string, msg *ChatMessage) (string, error) {
+func (api *API) PostMessageContext(ctx context.Context, channelId string, msg *ChatMessage) (*Message, error) {
+	var postMsgResp postMessageResponse
+
 	// post message
-	resp, err := api.doHTTPPostJSON("api/chat.postMessage", nil, postChatMessageRequest{
+	err := api.postJSON(ctx, "chat.postMessage", postChatMessageRequest{
 		ChannelId:   channelId,
 		ChatMessage: msg,
-	})
+	}, &postMsgResp)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to send request to post message: %v", err)
+		return nil, err
 	}
 
-	defer resp.Body.Close()
-
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to post message: status = %s", resp.Status)
-	}
-
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	// unmarshal response
-	var postMsgResp postMessageResponse
-
-	err = json.Unmarshal(b, &postMsgResp)
-	if err != nil {
-		return "", fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
+	// return result
+	posted := postMsgResp.Message
+	posted.Channel = postMsgResp.ChannelId
 
-	if !postMsgResp.OK {
-		return "", fmt.Errorf("failed to post message: %s", postMsgResp.Error)
+	if posted.Ts == "" {
+		posted.Ts = postMsgResp.Timestamp
 	}
 
-	// return result
-	return postMsgResp.Timestamp, nil
+	return &posted, nil
 }
 
 type postEphemeralMessageRequest struct {
@@ -155,128 +129,219 @@ type postEphemeralMessageRequest struct {
 }
 
 func (api *API) PostEphemeralMessage(channelId, userId string, msg *ChatMessage) error {
+	return api.PostEphemeralMessageContext(context.Background(), channelId, userId, msg)
+}
+
+func (api *API) PostEphemeralMessageContext(ctx context.Context, channelId, userId string, msg *ChatMessage) error {
 	// post ephemeral message
-	resp, err := api.doHTTPPostJSON("api/chat.postEphemeral", nil, postEphemeralMessageRequest{
+	return api.postJSON(ctx, "chat.postEphemeral", postEphemeralMessageRequest{
 		ChannelId:   channelId,
 		UserId:      userId,
 		ChatMessage: msg,
-	})
+	}, new(genericResponse))
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to send request to post ephemeral message: %v", err)
-	}
+type deleteMessageRequest struct {
+	ChannelID string `json:"channel"`
+	Timestamp string `json:"ts"`
+}
 
-	defer resp.Body.Close()
+func (api *API) DeleteMessage(channelId, timestamp string) error {
+	return api.DeleteMessageContext(context.Background(), channelId, timestamp)
+}
 
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to post ephemeral message: status = %s", resp.Status)
-	}
+func (api *API) DeleteMessageContext(ctx context.Context, channelId, timestamp string) error {
+	return api.postJSON(ctx, "chat.delete", &deleteMessageRequest{
+		ChannelID: channelId,
+		Timestamp: timestamp,
+	}, new(genericResponse))
+}
 
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+type updateMessageRequest struct {
+	ChannelID string `json:"channel"`
+	Timestamp string `json:"ts"`
+	*ChatMessage
+}
+
+func (api *API) UpdateMessage(channelId, timestamp string, msg *ChatMessage) error {
+	return api.UpdateMessageContext(context.Background(), channelId, timestamp, msg)
+}
+
+func (api *API) UpdateMessageContext(ctx context.Context, channelId, timestamp string, msg *ChatMessage) error {
+	return api.postJSON(ctx, "chat.update", updateMessageRequest{
+		ChannelID:   channelId,
+		Timestamp:   timestamp,
+		ChatMessage: msg,
+	}, new(genericResponse))
+}
+
+type ScheduledMessage struct {
+	ID          string `json:"id"`
+	ChannelId   string `json:"channel_id"`
+	PostAt      int64  `json:"post_at"`
+	DateCreated int64  `json:"date_created"`
+	Text        string `json:"text"`
+}
+
+type scheduleMessageRequest struct {
+	ChannelId string `json:"channel"`
+	PostAt    int64  `json:"post_at"`
+	*ChatMessage
+}
+
+type scheduleMessageResponse struct {
+	ChannelId          string `json:"channel"`
+	ScheduledMessageId string `json:"scheduled_message_id"`
+	PostAt             int64  `json:"post_at"`
+	genericResponse
+}
+
+// ScheduleMessage schedules msg to be posted to the channel at postAt, which
+// must be within 120 days
+func (api *API) ScheduleMessage(channelId string, postAt time.Time, msg *ChatMessage) (*ScheduledMessage, error) {
+	return api.ScheduleMessageContext(context.Background(), channelId, postAt, msg)
+}
+
+func (api *API) ScheduleMessageContext(ctx context.Context, channelId string, postAt time.Time, msg *ChatMessage) (*ScheduledMessage, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("empty message to schedule")
 	}
 
-	// unmarshal response
-	var gResp genericResponse
+	var resp scheduleMessageResponse
+
+	err := api.postJSON(ctx, "chat.scheduleMessage", scheduleMessageRequest{
+		ChannelId:   channelId,
+		PostAt:      postAt.Unix(),
+		ChatMessage: msg,
+	}, &resp)
 
-	err = json.Unmarshal(b, &gResp)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal response body: %v", err)
+		return nil, err
 	}
 
-	if !gResp.OK {
-		return fmt.Errorf("failed to post ephemeral message: %s", gResp.Error)
-	}
+	return &ScheduledMessage{
+		ID:        resp.ScheduledMessageId,
+		ChannelId: resp.ChannelId,
+		PostAt:    resp.PostAt,
+		Text:      msg.Text,
+	}, nil
+}
 
-	return nil
+type scheduledMessagesListResponse struct {
+	ScheduledMessages []ScheduledMessage `json:"scheduled_messages"`
+	genericResponse
 }
 
-type deleteMessageRequest struct {
-	ChannelID string `json:"channel"`
-	Timestamp string `json:"ts"`
+func (resp *scheduledMessagesListResponse) items() int {
+	return len(resp.ScheduledMessages)
 }
 
-func (api *API) DeleteMessage(channelId, timestamp string) error {
-	resp, err := api.doHTTPPostJSON("api/chat.delete", nil, &deleteMessageRequest{
-		ChannelID: channelId,
-		Timestamp: timestamp,
+// ListScheduledMessages returns messages scheduled by the app; all channels
+// are listed when channelId is empty
+func (api *API) ListScheduledMessages(channelId string, opts *PageOptions) ([]ScheduledMessage, error) {
+	return api.ListScheduledMessagesContext(context.Background(), channelId, opts)
+}
+
+func (api *API) ListScheduledMessagesContext(ctx context.Context, channelId string, opts *PageOptions) ([]ScheduledMessage, error) {
+	var messages []ScheduledMessage
+
+	err := api.EachScheduledMessageContext(ctx, channelId, opts, func(msg *ScheduledMessage) error {
+		messages = append(messages, *msg)
+		return nil
 	})
-	if err != nil {
-		return fmt.Errorf("failed to send request to delete message: %v", err)
-	}
 
-	defer resp.Body.Close()
+	return messages, err
+}
 
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to delete message: status = %s", resp.Status)
-	}
+func (api *API) EachScheduledMessage(channelId string, opts *PageOptions, fn func(*ScheduledMessage) error) error {
+	return api.EachScheduledMessageContext(context.Background(), channelId, opts, fn)
+}
 
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+func (api *API) EachScheduledMessageContext(ctx context.Context, channelId string, opts *PageOptions, fn func(*ScheduledMessage) error) error {
+	params := make(url.Values)
+	if channelId != "" {
+		params.Set("channel", channelId)
 	}
 
-	// unmarshal response
-	var genericResp genericResponse
+	p := api.newPager("chat.scheduledMessages.list", params, opts)
 
-	err = json.Unmarshal(b, &genericResp)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
+	var page *scheduledMessagesListResponse
 
-	if !genericResp.OK {
-		return fmt.Errorf("faild to delete message: %s", genericResp.Error)
-	}
+	return p.each(ctx, func() listResponse {
+		page = new(scheduledMessagesListResponse)
+		return page
+	}, func(i int) error {
+		return fn(&page.ScheduledMessages[i])
+	})
+}
 
-	return nil
+type deleteScheduledMessageRequest struct {
+	ChannelId          string `json:"channel"`
+	ScheduledMessageId string `json:"scheduled_message_id"`
 }
 
-type updateMessageRequest struct {
-	ChannelID string `json:"channel"`
-	Timestamp string `json:"ts"`
-	*ChatMessage
+func (api *API) DeleteScheduledMessage(channelId, scheduledMessageId string) error {
+	return api.DeleteScheduledMessageContext(context.Background(), channelId, scheduledMessageId)
 }
 
-func (api *API) UpdateMessage(channelId, timestamp string, msg *ChatMessage) error {
-	resp, err := api.doHTTPPostJSON("api/chat.update", nil, updateMessageRequest{
-		ChannelID:   channelId,
-		Timestamp:   timestamp,
-		ChatMessage: msg,
-	})
+func (api *API) DeleteScheduledMessageContext(ctx context.Context, channelId, scheduledMessageId string) error {
+	return api.postJSON(ctx, "chat.deleteScheduledMessage", deleteScheduledMessageRequest{
+		ChannelId:          channelId,
+		ScheduledMessageId: scheduledMessageId,
+	}, new(genericResponse))
+}
 
-	if err != nil {
-		return fmt.Errorf("failed to send request to update message: %v", err)
-	}
+type getPermalinkResponse struct {
+	ChannelId string `json:"channel"`
+	Permalink string `json:"permalink"`
+	genericResponse
+}
 
-	defer resp.Body.Close()
+func (api *API) GetPermalink(channelId, timestamp string) (string, error) {
+	return api.GetPermalinkContext(context.Background(), channelId, timestamp)
+}
 
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update message: status = %s", resp.Status)
-	}
+func (api *API) GetPermalinkContext(ctx context.Context, channelId, timestamp string) (string, error) {
+	params := make(url.Values)
+	params.Set("channel", channelId)
+	params.Set("message_ts", timestamp)
+
+	var resp getPermalinkResponse
 
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
+	err := api.get(ctx, "chat.getPermalink", params, &resp)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+		return "", err
 	}
 
-	// unmarshal response
-	var genericResp genericResponse
+	return resp.Permalink, nil
+}
 
-	err = json.Unmarshal(b, &genericResp)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal response body: %v", err)
+type unfurl struct {
+	Blocks []block.Block `json:"blocks"`
+}
+
+type unfurlRequest struct {
+	ChannelId string            `json:"channel"`
+	Timestamp string            `json:"ts"`
+	Unfurls   map[string]unfurl `json:"unfurls"`
+}
+
+// Unfurl renders previews of links in the message, where unfurls maps each
+// URL of a link_shared event to blocks of its preview
+func (api *API) Unfurl(channelId, timestamp string, unfurls map[string][]block.Block) error {
+	return api.UnfurlContext(context.Background(), channelId, timestamp, unfurls)
+}
+
+func (api *API) UnfurlContext(ctx context.Context, channelId, timestamp string, unfurls map[string][]block.Block) error {
+	req := unfurlRequest{
+		ChannelId: channelId,
+		Timestamp: timestamp,
+		Unfurls:   make(map[string]unfurl),
 	}
 
-	if !genericResp.OK {
-		return fmt.Errorf("faild to update message: %s", genericResp.Error)
+	for u, blocks := range unfurls {
+		req.Unfurls[u] = unfurl{Blocks: blocks}
 	}
 
-	return nil
+	return api.postJSON(ctx, "chat.unfurl", req, new(genericResponse))
 }
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/scryner/util.slack/block"
+)
+
+func TestPostMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if req["channel"] != "C1" || req["username"] != "oncall" || req["unfurl_links"] != false || req["reply_broadcast"] != true {
+			t.Errorf("unexpected request: %v", req)
+		}
+
+		metadata, _ := req["metadata"].(map[string]interface{})
+		if metadata["event_type"] != "incident_created" {
+			t.Errorf("unexpected metadata: %v", req["metadata"])
+		}
+
+		w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1.0","message":{"type":"message","bot_id":"B1","text":"hello","ts":"1.0","metadata":{"event_type":"incident_created","event_payload":{"id":"42"}}}}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	f := false
+
+	posted, err := slack.PostMessage("C1", &ChatMessage{
+		Text:           "hello",
+		ThreadTs:       "0.1",
+		ReplyBroadcast: true,
+		UnfurlLinks:    &f,
+		Username:       "oncall",
+		Metadata: &MessageMetadata{
+			EventType:    "incident_created",
+			EventPayload: map[string]interface{}{"id": "42"},
+		},
+	})
+	if err != nil {
+		t.Fatal("failed to post message:", err)
+	}
+
+	if posted.Channel != "C1" || posted.Ts != "1.0" || posted.BotID != "B1" || posted.Metadata == nil {
+		t.Errorf("unexpected posted message: %+v", posted)
+	}
+}
+
+func TestScheduledMessages(t *testing.T) {
+	postAt := time.Unix(1630000000, 0)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat.scheduleMessage":
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req["channel"] != "C1" || req["post_at"] != float64(postAt.Unix()) || req["text"] != "later" {
+				t.Errorf("unexpected request: %v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":"C1","scheduled_message_id":"Q1","post_at":1630000000}`))
+
+		case "/api/chat.scheduledMessages.list":
+			q := r.URL.Query()
+			if q.Get("channel") != "C1" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			if q.Get("cursor") == "" {
+				w.Write([]byte(`{"ok":true,"scheduled_messages":[{"id":"Q1","channel_id":"C1","post_at":1630000000,"text":"later"}],"response_metadata":{"next_cursor":"next"}}`))
+				return
+			}
+
+			w.Write([]byte(`{"ok":true,"scheduled_messages":[{"id":"Q2","channel_id":"C1","post_at":1630000060,"text":"much later"}]}`))
+
+		case "/api/chat.deleteScheduledMessage":
+			var req deleteScheduledMessageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req.ChannelId != "C1" || req.ScheduledMessageId != "Q1" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			w.Write([]byte(`{"ok":true}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	scheduled, err := slack.ScheduleMessage("C1", postAt, &ChatMessage{Text: "later"})
+	if err != nil {
+		t.Fatal("failed to schedule message:", err)
+	}
+
+	if scheduled.ID != "Q1" || scheduled.ChannelId != "C1" || scheduled.PostAt != postAt.Unix() || scheduled.Text != "later" {
+		t.Errorf("unexpected scheduled message: %+v", scheduled)
+	}
+
+	// nil message is rejected instead of panicking
+	if _, err = slack.ScheduleMessage("C1", postAt, nil); err == nil {
+		t.Error("nil message must be rejected")
+	}
+
+	messages, err := slack.ListScheduledMessages("C1", nil)
+	if err != nil {
+		t.Fatal("failed to list scheduled messages:", err)
+	}
+
+	if len(messages) != 2 || messages[0].ID != "Q1" || messages[1].ID != "Q2" || messages[1].Text != "much later" {
+		t.Errorf("unexpected scheduled messages: %+v", messages)
+	}
+
+	if err = slack.DeleteScheduledMessage("C1", "Q1"); err != nil {
+		t.Fatal("failed to delete scheduled message:", err)
+	}
+}
+
+func TestPermalinkAndUnfurl(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat.getPermalink":
+			q := r.URL.Query()
+			if q.Get("channel") != "C1" || q.Get("message_ts") != "1.0" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":"C1","permalink":"https://team.slack.com/archives/C1/p10"}`))
+
+		case "/api/chat.unfurl":
+			var req struct {
+				Channel string `json:"channel"`
+				Ts      string `json:"ts"`
+				Unfurls map[string]struct {
+					Blocks []map[string]interface{} `json:"blocks"`
+				} `json:"unfurls"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			preview, ok := req.Unfurls["https://example.com/1"]
+			if req.Channel != "C1" || req.Ts != "1.0" || len(req.Unfurls) != 1 || !ok {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			if len(preview.Blocks) != 1 || preview.Blocks[0]["type"] != "divider" {
+				t.Errorf("unexpected preview: %+v", preview.Blocks)
+			}
+
+			w.Write([]byte(`{"ok":true}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	permalink, err := slack.GetPermalink("C1", "1.0")
+	if err != nil {
+		t.Fatal("failed to get permalink:", err)
+	}
+
+	if permalink != "https://team.slack.com/archives/C1/p10" {
+		t.Errorf("unexpected permalink: %s", permalink)
+	}
+
+	err = slack.Unfurl("C1", "1.0", map[string][]block.Block{
+		"https://example.com/1": {block.Divider()},
+	})
+	if err != nil {
+		t.Fatal("failed to unfurl:", err)
+	}
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Conversation struct {
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	IsChannel      bool              `json:"is_channel"`
+	IsGroup        bool              `json:"is_group"`
+	IsIM           bool              `json:"is_im"`
+	IsMpIM         bool              `json:"is_mpim"`
+	IsPrivate      bool              `json:"is_private"`
+	IsArchived     bool              `json:"is_archived"`
+	IsGeneral      bool              `json:"is_general"`
+	IsShared       bool              `json:"is_shared"`
+	IsExtShared    bool              `json:"is_ext_shared"`
+	IsOrgShared    bool              `json:"is_org_shared"`
+	IsMember       bool              `json:"is_member"`
+	Created        int64             `json:"created"`
+	Creator        string            `json:"creator"`
+	User           string            `json:"user"` // the other party of an IM
+	NameNormalized string            `json:"name_normalized"`
+	NumMembers     int               `json:"num_members"`
+	ContextTeamID  string            `json:"context_team_id"`
+	LastRead       string            `json:"last_read"`
+	Topic          ConversationTopic `json:"topic"`
+	Purpose        ConversationTopic `json:"purpose"`
+}
+
+type ConversationTopic struct {
+	Value   string `json:"value"`
+	Creator string `json:"creator"`
+	LastSet int64  `json:"last_set"`
+}
+
+type ListConversationsParams struct {
+	Types           []string // public_channel, private_channel, mpim, im
+	ExcludeArchived bool
+	TeamID          string
+}
+
+func (params *ListConversationsParams) values() url.Values {
+	vals := make(url.Values)
+	if params == nil {
+		return vals
+	}
+
+	if len(params.Types) > 0 {
+		vals.Set("types", strings.Join(params.Types, ","))
+	}
+
+	if params.ExcludeArchived {
+		vals.Set("exclude_archived", strconv.FormatBool(params.ExcludeArchived))
+	}
+
+	if params.TeamID != "" {
+		vals.Set("team_id", params.TeamID)
+	}
+
+	return vals
+}
+
+type conversationsListResponse struct {
+	Channels []Conversation `json:"channels"`
+	genericResponse
+}
+
+func (resp *conversationsListResponse) items() int {
+	return len(resp.Channels)
+}
+
+func (api *API) ListConversations(params *ListConversationsParams, opts *PageOptions) ([]Conversation, error) {
+	return api.ListConversationsContext(context.Background(), params, opts)
+}
+
+func (api *API) ListConversationsContext(ctx context.Context, params *ListConversationsParams, opts *PageOptions) ([]Conversation, error) {
+	var conversations []Conversation
+
+	err := api.EachConversationContext(ctx, params, opts, func(conversation *Conversation) error {
+		conversations = append(conversations, *conversation)
+		return nil
+	})
+
+	return conversations, err
+}
+
+func (api *API) EachConversation(params *ListConversationsParams, opts *PageOptions, fn func(*Conversation) error) error {
+	return api.EachConversationContext(context.Background(), params, opts, fn)
+}
+
+func (api *API) EachConversationContext(ctx context.Context, params *ListConversationsParams, opts *PageOptions, fn func(*Conversation) error) error {
+	p := api.newPager("conversations.list", params.values(), opts)
+
+	var page *conversationsListResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(conversationsListResponse)
+		return page
+	}, func(i int) error {
+		return fn(&page.Channels[i])
+	})
+}
+
+type conversationMembersResponse struct {
+	Members []string `json:"members"`
+	genericResponse
+}
+
+func (resp *conversationMembersResponse) items() int {
+	return len(resp.Members)
+}
+
+// ListConversationMembers returns IDs of users in the channel
+func (api *API) ListConversationMembers(channelId string, opts *PageOptions) ([]string, error) {
+	return api.ListConversationMembersContext(context.Background(), channelId, opts)
+}
+
+func (api *API) ListConversationMembersContext(ctx context.Context, channelId string, opts *PageOptions) ([]string, error) {
+	var members []string
+
+	err := api.EachConversationMemberContext(ctx, channelId, opts, func(userId string) error {
+		members = append(members, userId)
+		return nil
+	})
+
+	return members, err
+}
+
+func (api *API) EachConversationMember(channelId string, opts *PageOptions, fn func(userId string) error) error {
+	return api.EachConversationMemberContext(context.Background(), channelId, opts, fn)
+}
+
+func (api *API) EachConversationMemberContext(ctx context.Context, channelId string, opts *PageOptions, fn func(userId string) error) error {
+	params := make(url.Values)
+	params.Set("channel", channelId)
+
+	p := api.newPager("conversations.members", params, opts)
+
+	var page *conversationMembersResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(conversationMembersResponse)
+		return page
+	}, func(i int) error {
+		return fn(page.Members[i])
+	})
+}
+
+type conversationResponse struct {
+	Channel Conversation `json:"channel"`
+	genericResponse
+}
+
+type createConversationRequest struct {
+	Name      string `json:"name"`
+	IsPrivate bool   `json:"is_private"`
+}
+
+func (api *API) CreateConversation(name string, isPrivate bool) (*Conversation, error) {
+	return api.CreateConversationContext(context.Background(), name, isPrivate)
+}
+
+func (api *API) CreateConversationContext(ctx context.Context, name string, isPrivate bool) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.create", createConversationRequest{
+		Name:      name,
+		IsPrivate: isPrivate,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+func (api *API) GetConversationInfo(channelId string) (*Conversation, error) {
+	return api.GetConversationInfoContext(context.Background(), channelId)
+}
+
+func (api *API) GetConversationInfoContext(ctx context.Context, channelId string) (*Conversation, error) {
+	params := make(url.Values)
+	params.Set("channel", channelId)
+	params.Set("include_num_members", "true")
+
+	var resp conversationResponse
+
+	err := api.get(ctx, "conversations.info", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+type channelRequest struct {
+	ChannelId string `json:"channel"`
+}
+
+func (api *API) JoinConversation(channelId string) (*Conversation, error) {
+	return api.JoinConversationContext(context.Background(), channelId)
+}
+
+func (api *API) JoinConversationContext(ctx context.Context, channelId string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.join", channelRequest{
+		ChannelId: channelId,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+func (api *API) LeaveConversation(channelId string) error {
+	return api.LeaveConversationContext(context.Background(), channelId)
+}
+
+func (api *API) LeaveConversationContext(ctx context.Context, channelId string) error {
+	return api.postJSON(ctx, "conversations.leave", channelRequest{
+		ChannelId: channelId,
+	}, new(genericResponse))
+}
+
+type inviteConversationRequest struct {
+	ChannelId string `json:"channel"`
+	Users     string `json:"users"`
+}
+
+func (api *API) InviteToConversation(channelId string, userIds ...string) (*Conversation, error) {
+	return api.InviteToConversationContext(context.Background(), channelId, userIds...)
+}
+
+func (api *API) InviteToConversationContext(ctx context.Context, channelId string, userIds ...string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.invite", inviteConversationRequest{
+		ChannelId: channelId,
+		Users:     strings.Join(userIds, ","),
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+type kickConversationRequest struct {
+	ChannelId string `json:"channel"`
+	User      string `json:"user"`
+}
+
+func (api *API) KickFromConversation(channelId, userId string) error {
+	return api.KickFromConversationContext(context.Background(), channelId, userId)
+}
+
+func (api *API) KickFromConversationContext(ctx context.Context, channelId, userId string) error {
+	return api.postJSON(ctx, "conversations.kick", kickConversationRequest{
+		ChannelId: channelId,
+		User:      userId,
+	}, new(genericResponse))
+}
+
+func (api *API) ArchiveConversation(channelId string) error {
+	return api.ArchiveConversationContext(context.Background(), channelId)
+}
+
+func (api *API) ArchiveConversationContext(ctx context.Context, channelId string) error {
+	return api.postJSON(ctx, "conversations.archive", channelRequest{
+		ChannelId: channelId,
+	}, new(genericResponse))
+}
+
+func (api *API) UnarchiveConversation(channelId string) error {
+	return api.UnarchiveConversationContext(context.Background(), channelId)
+}
+
+func (api *API) UnarchiveConversationContext(ctx context.Context, channelId string) error {
+	return api.postJSON(ctx, "conversations.unarchive", channelRequest{
+		ChannelId: channelId,
+	}, new(genericResponse))
+}
+
+type renameConversationRequest struct {
+	ChannelId string `json:"channel"`
+	Name      string `json:"name"`
+}
+
+func (api *API) RenameConversation(channelId, name string) (*Conversation, error) {
+	return api.RenameConversationContext(context.Background(), channelId, name)
+}
+
+func (api *API) RenameConversationContext(ctx context.Context, channelId, name string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.rename", renameConversationRequest{
+		ChannelId: channelId,
+		Name:      name,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+type setTopicRequest struct {
+	ChannelId string `json:"channel"`
+	Topic     string `json:"topic"`
+}
+
+func (api *API) SetConversationTopic(channelId, topic string) (*Conversation, error) {
+	return api.SetConversationTopicContext(context.Background(), channelId, topic)
+}
+
+func (api *API) SetConversationTopicContext(ctx context.Context, channelId, topic string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.setTopic", setTopicRequest{
+		ChannelId: channelId,
+		Topic:     topic,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+type setPurposeRequest struct {
+	ChannelId string `json:"channel"`
+	Purpose   string `json:"purpose"`
+}
+
+func (api *API) SetConversationPurpose(channelId, purpose string) (*Conversation, error) {
+	return api.SetConversationPurposeContext(context.Background(), channelId, purpose)
+}
+
+func (api *API) SetConversationPurposeContext(ctx context.Context, channelId, purpose string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.setPurpose", setPurposeRequest{
+		ChannelId: channelId,
+		Purpose:   purpose,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Channel, nil
+}
+
+type openConversationRequest struct {
+	Users    string `json:"users"`
+	ReturnIM bool   `json:"return_im,omitempty"`
+}
+
+// OpenConversation opens (or resumes) a DM with a user or a multi-person DM
+// with up to 8 users
+func (api *API) OpenConversation(userIds ...string) (*Conversation, error) {
+	return api.OpenConversationContext(context.Background(), userIds...)
+}
+
+func (api *API) OpenConversationContext(ctx context.Context, userIds ...string) (*Conversation, error) {
+	var resp conversationResponse
+
+	err := api.postJSON(ctx, "conversations.open", openConversationRequest{
+		Users: strings.Join(userIds, ","),
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Channel.ID == "" {
+		return nil, fmt.Errorf("empty channel id")
+	}
+
+	return &resp.Channel, nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManageConversations(t *testing.T) {
+	var method string
+	var req map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = strings.TrimPrefix(r.URL.Path, "/api/")
+
+		req = nil
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Write([]byte(`{"ok":true,"channel":{"id":"C1","name":"general","topic":{"value":"news"},"purpose":{"value":"chat"}}}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	for _, tc := range []struct {
+		method   string
+		expected map[string]interface{}
+		call     func() (*Conversation, error)
+	}{
+		{"conversations.create", map[string]interface{}{"name": "general", "is_private": true}, func() (*Conversation, error) {
+			return slack.CreateConversation("general", true)
+		}},
+		{"conversations.join", map[string]interface{}{"channel": "C1"}, func() (*Conversation, error) {
+			return slack.JoinConversation("C1")
+		}},
+		{"conversations.leave", map[string]interface{}{"channel": "C1"}, func() (*Conversation, error) {
+			return nil, slack.LeaveConversation("C1")
+		}},
+		{"conversations.invite", map[string]interface{}{"channel": "C1", "users": "U1,U2"}, func() (*Conversation, error) {
+			return slack.InviteToConversation("C1", "U1", "U2")
+		}},
+		{"conversations.kick", map[string]interface{}{"channel": "C1", "user": "U1"}, func() (*Conversation, error) {
+			return nil, slack.KickFromConversation("C1", "U1")
+		}},
+		{"conversations.archive", map[string]interface{}{"channel": "C1"}, func() (*Conversation, error) {
+			return nil, slack.ArchiveConversation("C1")
+		}},
+		{"conversations.unarchive", map[string]interface{}{"channel": "C1"}, func() (*Conversation, error) {
+			return nil, slack.UnarchiveConversation("C1")
+		}},
+		{"conversations.rename", map[string]interface{}{"channel": "C1", "name": "general"}, func() (*Conversation, error) {
+			return slack.RenameConversation("C1", "general")
+		}},
+		{"conversations.setTopic", map[string]interface{}{"channel": "C1", "topic": "news"}, func() (*Conversation, error) {
+			return slack.SetConversationTopic("C1", "news")
+		}},
+		{"conversations.setPurpose", map[string]interface{}{"channel": "C1", "purpose": "chat"}, func() (*Conversation, error) {
+			return slack.SetConversationPurpose("C1", "chat")
+		}},
+		{"conversations.open", map[string]interface{}{"users": "U1,U2"}, func() (*Conversation, error) {
+			return slack.OpenConversation("U1", "U2")
+		}},
+	} {
+		conversation, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: failed to call: %v", tc.method, err)
+			continue
+		}
+
+		if method != tc.method {
+			t.Errorf("%s: unexpected method: %s", tc.method, method)
+		}
+
+		if !reflect.DeepEqual(req, tc.expected) {
+			t.Errorf("%s: unexpected request: %v", tc.method, req)
+		}
+
+		if conversation != nil && (conversation.ID != "C1" || conversation.Name != "general") {
+			t.Errorf("%s: unexpected conversation: %+v", tc.method, conversation)
+		}
+	}
+}
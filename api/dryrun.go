@@ -0,0 +1,325 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// readMethods do not change anything in the workspace; every other method but
+// oauth.v2.access (see DryRun) is treated as a write in dry-run, so unknown
+// methods are never sent
+var readMethods = map[string]bool{
+	"auth.test":                   true,
+	"team.info":                   true,
+	"users.info":                  true,
+	"users.list":                  true,
+	"users.lookupByEmail":         true,
+	"users.profile.get":           true,
+	"users.getPresence":           true,
+	"dnd.info":                    true,
+	"dnd.teamInfo":                true,
+	"conversations.list":          true,
+	"conversations.info":          true,
+	"conversations.members":       true,
+	"conversations.history":       true,
+	"conversations.replies":       true,
+	"chat.getPermalink":           true,
+	"chat.scheduledMessages.list": true,
+	"reactions.get":               true,
+	"pins.list":                   true,
+	"bookmarks.list":              true,
+	"files.list":                  true,
+	"usergroups.list":             true,
+	"usergroups.users.list":       true,
+}
+
+// DryRunRecord is a write call which was not sent
+type DryRunRecord struct {
+	Time   time.Time   `json:"time"`
+	Method string      `json:"method"`
+	Params url.Values  `json:"params,omitempty"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// DryRunSink receives write calls suppressed by dry-run
+type DryRunSink interface {
+	Record(record *DryRunRecord) error
+}
+
+type logSink func(format string, args ...interface{})
+
+func (logf logSink) Record(record *DryRunRecord) error {
+	b, err := json.Marshal(record.Body)
+	if err != nil {
+		return err
+	}
+
+	logf("slack api dry-run: method=%s params=%s body=%s", record.Method, record.Params.Encode(), b)
+	return nil
+}
+
+// LogSink logs suppressed calls by logf
+func LogSink(logf func(format string, args ...interface{})) DryRunSink {
+	return logSink(logf)
+}
+
+type jsonlSink struct {
+	w    io.Writer
+	lock *sync.Mutex
+}
+
+func (sink *jsonlSink) Record(record *DryRunRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	_, err = sink.w.Write(append(b, '\n'))
+	return err
+}
+
+// JSONLSink writes suppressed calls to w as JSON lines (e.g., to a file)
+func JSONLSink(w io.Writer) DryRunSink {
+	return &jsonlSink{
+		w:    w,
+		lock: new(sync.Mutex),
+	}
+}
+
+// MemorySink keeps suppressed calls in memory (e.g., for tests)
+type MemorySink struct {
+	records []DryRunRecord
+	lock    *sync.Mutex
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		lock: new(sync.Mutex),
+	}
+}
+
+func (sink *MemorySink) Record(record *DryRunRecord) error {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	sink.records = append(sink.records, *record)
+	return nil
+}
+
+// Records returns suppressed calls in order
+func (sink *MemorySink) Records() []DryRunRecord {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	return append([]DryRunRecord(nil), sink.records...)
+}
+
+func (sink *MemorySink) Reset() {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	sink.records = nil
+}
+
+type DryRunConfig struct {
+	// Sink receives write calls; they are dropped silently if nil
+	Sink DryRunSink
+
+	// PassThroughReads sends read methods to the server (see ServerAddress);
+	// otherwise they get empty successful responses
+	PassThroughReads bool
+}
+
+// DryRun turns write methods (e.g., PostMessage, PublishView) into no-ops
+// recorded to the sink; they get successful responses with made-up ids.
+// oauth.v2.access is always sent, since exchanging credentials changes
+// nothing in the workspace and a made-up token would be saved as an
+// installation.
+func DryRun(config DryRunConfig) Option {
+	return func(api *API) error {
+		api.dryRun = &config
+		return nil
+	}
+}
+
+// answers reports whether calls of the method are answered by dry-run instead
+// of Slack, which are not rate limited then; config may be nil
+func (config *DryRunConfig) answers(method string) bool {
+	if config == nil || method == "oauth.v2.access" {
+		return false
+	}
+
+	return !(readMethods[method] && config.PassThroughReads)
+}
+
+// intercept answers calls instead of Slack; it is the innermost interceptor
+// so that other interceptors see the calls as usual
+func (config *DryRunConfig) intercept(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+	if !config.answers(call.Method) {
+		return next(ctx, call)
+	}
+
+	if readMethods[call.Method] {
+		return fakeResult([]byte(`{"ok":true}`)), nil
+	}
+
+	now := time.Now()
+
+	if config.Sink != nil {
+		err := config.Sink.Record(&DryRunRecord{
+			Time:   now,
+			Method: call.Method,
+			Params: call.Params,
+			Body:   call.Body,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to record dry-run of %s: %w", call.Method, err)
+		}
+	}
+
+	b, err := json.Marshal(dryRunResponse(call, now))
+	if err != nil {
+		return nil, err
+	}
+
+	return fakeResult(b), nil
+}
+
+func fakeResult(body []byte) *Result {
+	return &Result{
+		StatusCode: http.StatusOK,
+		OK:         true,
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		},
+	}
+}
+
+// dryRunResponse makes up a plausible response of the write method from its
+// arguments, so that callers can go on (e.g., use ts of a posted message)
+func dryRunResponse(call *Call, now time.Time) map[string]interface{} {
+	args := make(map[string]interface{})
+
+	if call.Body != nil {
+		if b, err := json.Marshal(call.Body); err == nil {
+			json.Unmarshal(b, &args)
+		}
+	}
+
+	for k := range call.Params {
+		args[k] = call.Params.Get(k)
+	}
+
+	str := func(key string) string {
+		s, _ := args[key].(string)
+		return s
+	}
+
+	ts := TimeToTs(now)
+	resp := map[string]interface{}{"ok": true}
+
+	switch method := call.Method; {
+	case method == "chat.postMessage" || method == "chat.update":
+		if method == "chat.update" {
+			ts = str("ts")
+		}
+
+		resp["channel"] = str("channel")
+		resp["ts"] = ts
+		resp["message"] = map[string]interface{}{
+			"type":   "message",
+			"text":   str("text"),
+			"ts":     ts,
+			"blocks": args["blocks"],
+		}
+
+	case method == "chat.postEphemeral":
+		resp["message_ts"] = ts
+
+	case method == "chat.scheduleMessage":
+		resp["channel"] = str("channel")
+		resp["scheduled_message_id"] = fakeId("Q")
+		resp["post_at"] = args["post_at"]
+
+	case strings.HasPrefix(method, "conversations."):
+		channel := map[string]interface{}{"id": str("channel")}
+		if channel["id"] == "" {
+			channel["id"] = fakeId("C")
+		}
+
+		if name := str("name"); name != "" {
+			channel["name"] = name
+		}
+
+		resp["channel"] = channel
+
+	case strings.HasPrefix(method, "views."):
+		view, _ := args["view"].(map[string]interface{})
+		if view == nil {
+			view = make(map[string]interface{})
+		}
+
+		view["id"] = str("view_id")
+		if view["id"] == "" {
+			view["id"] = fakeId("V")
+		}
+
+		view["hash"] = fakeId("")
+		resp["view"] = view
+
+	case method == "files.getUploadURLExternal":
+		resp["file_id"] = fakeId("F")
+		resp["upload_url"] = ""
+
+	case method == "files.completeUploadExternal":
+		files, _ := args["files"].([]interface{})
+		resp["files"] = files
+
+	case strings.HasPrefix(method, "bookmarks."):
+		resp["bookmark"] = map[string]interface{}{
+			"id":         fakeId("Bk"),
+			"channel_id": str("channel_id"),
+			"title":      str("title"),
+			"link":       str("link"),
+		}
+
+	case strings.HasPrefix(method, "usergroups."):
+		id := str("usergroup")
+		if id == "" {
+			id = fakeId("S")
+		}
+
+		resp["usergroup"] = map[string]interface{}{
+			"id":     id,
+			"name":   str("name"),
+			"handle": str("handle"),
+		}
+	}
+
+	return resp
+}
+
+// fakeId makes an id which is obviously not from Slack
+func fakeId(prefix string) string {
+	b := make([]byte, 4)
+	rand.Read(b)
+
+	return prefix + "DRYRUN" + strings.ToUpper(hex.EncodeToString(b))
+}
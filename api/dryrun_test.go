@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDryRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/conversations.info" {
+			t.Errorf("write must not be sent: %s", r.URL.Path)
+		}
+
+		w.Write([]byte(`{"ok":true,"channel":{"id":"C1","name":"general"}}`))
+	}))
+	defer ts.Close()
+
+	sink := NewMemorySink()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), DryRun(DryRunConfig{
+		Sink:             sink,
+		PassThroughReads: true,
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	// reads pass through
+	conv, err := slack.GetConversationInfo("C1")
+	if err != nil || conv.Name != "general" {
+		t.Fatalf("read must pass through: %v, %+v", err, conv)
+	}
+
+	// writes are recorded
+	posted, err := slack.PostMessage("C1", &ChatMessage{Text: "hello"})
+	if err != nil {
+		t.Fatal("failed to post message:", err)
+	}
+
+	if posted.Channel != "C1" || posted.Ts == "" || posted.Text != "hello" {
+		t.Errorf("unexpected message: %+v", posted)
+	}
+
+	view, err := slack.OpenView("trigger", &View{Type: "modal", CallbackId: "cb"})
+	if err != nil {
+		t.Fatal("failed to open view:", err)
+	}
+
+	if !strings.Contains(view.ID, "DRYRUN") || view.CallbackID != "cb" {
+		t.Errorf("unexpected view: %+v", view)
+	}
+
+	file, err := slack.UploadFile(strings.NewReader("content"), &UploadFileParams{Filename: "a.txt", Title: "A"})
+	if err != nil {
+		t.Fatal("failed to upload file:", err)
+	}
+
+	if file.Title != "A" {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	var methods []string
+	for _, record := range sink.Records() {
+		methods = append(methods, record.Method)
+	}
+
+	if strings.Join(methods, ",") != "chat.postMessage,views.open,files.getUploadURLExternal,files.completeUploadExternal" {
+		t.Errorf("unexpected records: %v", methods)
+	}
+}
+
+func TestJSONLSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	slack, err := New("xoxb-test", ServerAddress("http://127.0.0.1:0"), DryRun(DryRunConfig{
+		Sink: JSONLSink(&buf),
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.DeleteMessage("C1", "1.0"); err != nil {
+		t.Fatal("failed to delete message:", err)
+	}
+
+	// reads get empty responses without pass-through
+	if pins, err := slack.ListPins("C1"); err != nil || len(pins) != 0 {
+		t.Fatalf("read must be answered empty: %v, %v", err, pins)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("only the write must be recorded: %q", buf.String())
+	}
+
+	var record DryRunRecord
+	if err = json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal("invalid record:", err)
+	}
+
+	if record.Method != "chat.delete" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestDryRunSkipsRateLimiter(t *testing.T) {
+	slack, err := New("xoxb-test", ServerAddress("http://127.0.0.1:0"), RateLimit(RateLimitConfig{Pacing: true}), DryRun(DryRunConfig{}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	// chat.postMessage is paced to 1 per second per channel when sent
+	started := time.Now()
+
+	for i := 0; i < 5; i++ {
+		if _, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err != nil {
+			t.Fatal("failed to post message:", err)
+		}
+	}
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("calls answered by dry-run must not wait for the rate limiter: %s", elapsed)
+	}
+
+	// credentials are always exchanged
+	config := &DryRunConfig{}
+	if config.answers("oauth.v2.access") || !config.answers("users.info") {
+		t.Error("oauth.v2.access must be sent while reads are answered")
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Error is returned by API methods when Slack rejects a call, either by a
+// non-200 HTTP status or by a response with "ok": false
+type Error struct {
+	Method     string        // e.g., "chat.postMessage"
+	StatusCode int           // HTTP status code
+	Code       string        // the "error" field, e.g., "channel_not_found"
+	Warning    string        // the "warning" field
+	Messages   []string      // response_metadata.messages
+	RetryAfter time.Duration // set when rate limited
+}
+
+func (e *Error) Error() string {
+	var detail string
+
+	switch {
+	case e.Code != "" && len(e.Messages) > 0:
+		detail = fmt.Sprintf("%s (%s)", e.Code, strings.Join(e.Messages, ", "))
+	case e.Code != "":
+		detail = e.Code
+	default:
+		detail = fmt.Sprintf("status = %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	if e.Method == "" {
+		return fmt.Sprintf("slack api failed: %s", detail)
+	}
+
+	return fmt.Sprintf("%s failed: %s", e.Method, detail)
+}
+
+// Is reports whether e matches target; a target matches when its non-zero
+// Code and StatusCode are equal to those of e, which lets the sentinels below
+// be used with errors.Is
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	if t.Code == "" && t.StatusCode == 0 {
+		return false
+	}
+
+	if t.Code != "" && normalizeErrorCode(t.Code) != normalizeErrorCode(e.Code) {
+		return false
+	}
+
+	if t.StatusCode != 0 && t.StatusCode != e.StatusCode {
+		return false
+	}
+
+	return true
+}
+
+func normalizeErrorCode(code string) string {
+	switch code {
+	case "users_not_found":
+		// users.lookupByEmail answers users_not_found, users.info user_not_found
+		return "user_not_found"
+	default:
+		return code
+	}
+}
+
+var (
+	ErrUserNotFound       = &Error{Code: "users_not_found"}
+	ErrChannelNotFound    = &Error{Code: "channel_not_found"}
+	ErrNotInChannel       = &Error{Code: "not_in_channel"}
+	ErrIsArchived         = &Error{Code: "is_archived"}
+	ErrMessageNotFound    = &Error{Code: "message_not_found"}
+	ErrCantUpdateMessage  = &Error{Code: "cant_update_message"}
+	ErrCantDeleteMessage  = &Error{Code: "cant_delete_message"}
+	ErrMsgTooLong         = &Error{Code: "msg_too_long"}
+	ErrNoText             = &Error{Code: "no_text"}
+	ErrExpiredTriggerId   = &Error{Code: "expired_trigger_id"}
+	ErrHashConflict       = &Error{Code: "hash_conflict"}
+	ErrViewNotFound       = &Error{Code: "not_found"}
+	ErrNotAuthed          = &Error{Code: "not_authed"}
+	ErrInvalidAuth        = &Error{Code: "invalid_auth"}
+	ErrAccountInactive    = &Error{Code: "account_inactive"}
+	ErrTokenRevoked       = &Error{Code: "token_revoked"}
+	ErrTokenExpired       = &Error{Code: "token_expired"}
+	ErrMissingScope       = &Error{Code: "missing_scope"}
+	ErrRateLimited        = &Error{Code: "ratelimited"}
+	ErrInternalError      = &Error{Code: "internal_error"}
+	ErrFatalError         = &Error{Code: "fatal_error"}
+	ErrServiceUnavailable = &Error{Code: "service_unavailable"}
+)
+
+type responseMetadata struct {
+	Messages   []string `json:"messages"`
+	Warnings   []string `json:"warnings"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+type genericResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Warning          string           `json:"warning"`
+	ResponseMetadata responseMetadata `json:"response_metadata"`
+}
+
+func (resp *genericResponse) result() *genericResponse {
+	return resp
+}
+
+// response is implemented by every response type embedding genericResponse
+type response interface {
+	result() *genericResponse
+}
+
+// headerReceiver is implemented by responses which need HTTP headers too
+// (e.g., scopes of auth.test)
+type headerReceiver interface {
+	receiveHeader(header http.Header)
+}
+
+// decodeResponse reads the body of resp into v and turns failures into *Error
+func decodeResponse(method string, resp *http.Response, v response) error {
+	defer resp.Body.Close()
+
+	// read response body
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response body: %w", method, err)
+	}
+
+	// check result
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{
+			Method:     method,
+			StatusCode: resp.StatusCode,
+		}
+
+		// slack may describe the failure in body; it is best effort
+		var gResp genericResponse
+		if json.Unmarshal(b, &gResp) == nil {
+			apiErr.Code = gResp.Error
+			apiErr.Warning = gResp.Warning
+			apiErr.Messages = gResp.ResponseMetadata.Messages
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header)
+
+			if apiErr.Code == "" {
+				apiErr.Code = ErrRateLimited.Code
+			}
+		}
+
+		return apiErr
+	}
+
+	// unmarshal response
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal %s response body: %w", method, err)
+	}
+
+	if hr, ok := v.(headerReceiver); ok {
+		hr.receiveHeader(resp.Header)
+	}
+
+	if r := v.result(); !r.OK {
+		return &Error{
+			Method:     method,
+			StatusCode: resp.StatusCode,
+			Code:       r.Error,
+			Warning:    r.Warning,
+			Messages:   r.ResponseMetadata.Messages,
+		}
+	}
+
+	return nil
+}
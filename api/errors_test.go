@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found","warning":"missing_charset","response_metadata":{"messages":["[ERROR] no such channel"]}}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	err = slack.DeleteMessage("C1", "1.0")
+	if !errors.Is(err, ErrChannelNotFound) {
+		t.Errorf("error must be channel_not_found, but %v", err)
+	}
+
+	if errors.Is(err, ErrNotInChannel) {
+		t.Errorf("error must not be not_in_channel")
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error must be *Error, but %T", err)
+	}
+
+	if apiErr.Method != "chat.delete" || apiErr.Warning != "missing_charset" || len(apiErr.Messages) != 1 {
+		t.Errorf("unexpected error fields: %+v", apiErr)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	_, err = slack.GetUserInfo("U1")
+	if !errors.Is(err, &Error{StatusCode: http.StatusServiceUnavailable}) {
+		t.Errorf("error must have status 503, but %v", err)
+	}
+}
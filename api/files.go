@@ -0,0 +1,297 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type File struct {
+	ID                 string   `json:"id"`
+	Created            int64    `json:"created"`
+	Timestamp          int64    `json:"timestamp"`
+	Name               string   `json:"name"`
+	Title              string   `json:"title"`
+	Mimetype           string   `json:"mimetype"`
+	Filetype           string   `json:"filetype"`
+	PrettyType         string   `json:"pretty_type"`
+	User               string   `json:"user"`
+	Size               int64    `json:"size"`
+	IsExternal         bool     `json:"is_external"`
+	IsPublic           bool     `json:"is_public"`
+	URLPrivate         string   `json:"url_private"`
+	URLPrivateDownload string   `json:"url_private_download"`
+	Permalink          string   `json:"permalink"`
+	PermalinkPublic    string   `json:"permalink_public"`
+	Channels           []string `json:"channels"`
+	Groups             []string `json:"groups"`
+	IMs                []string `json:"ims"`
+}
+
+type ListFilesParams struct {
+	Channel string
+	User    string
+	Types   []string // all, spaces, snippets, images, gdocs, zips, pdfs
+	TsFrom  time.Time
+	TsTo    time.Time
+	TeamID  string
+}
+
+func (params *ListFilesParams) values() url.Values {
+	vals := make(url.Values)
+	if params == nil {
+		return vals
+	}
+
+	if params.Channel != "" {
+		vals.Set("channel", params.Channel)
+	}
+
+	if params.User != "" {
+		vals.Set("user", params.User)
+	}
+
+	if len(params.Types) > 0 {
+		vals.Set("types", strings.Join(params.Types, ","))
+	}
+
+	if !params.TsFrom.IsZero() {
+		vals.Set("ts_from", strconv.FormatInt(params.TsFrom.Unix(), 10))
+	}
+
+	if !params.TsTo.IsZero() {
+		vals.Set("ts_to", strconv.FormatInt(params.TsTo.Unix(), 10))
+	}
+
+	if params.TeamID != "" {
+		vals.Set("team_id", params.TeamID)
+	}
+
+	return vals
+}
+
+type paging struct {
+	Count int `json:"count"`
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Pages int `json:"pages"`
+}
+
+type filesListResponse struct {
+	Files  []File `json:"files"`
+	Paging paging `json:"paging"`
+	genericResponse
+}
+
+func (resp *filesListResponse) items() int {
+	return len(resp.Files)
+}
+
+// files.list is paged by page numbers rather than cursors
+func (resp *filesListResponse) nextPage() (string, string) {
+	if resp.Paging.Page >= resp.Paging.Pages {
+		return "page", ""
+	}
+
+	return "page", strconv.Itoa(resp.Paging.Page + 1)
+}
+
+func (api *API) ListFiles(params *ListFilesParams, opts *PageOptions) ([]File, error) {
+	return api.ListFilesContext(context.Background(), params, opts)
+}
+
+func (api *API) ListFilesContext(ctx context.Context, params *ListFilesParams, opts *PageOptions) ([]File, error) {
+	var files []File
+
+	err := api.EachFileContext(ctx, params, opts, func(file *File) error {
+		files = append(files, *file)
+		return nil
+	})
+
+	return files, err
+}
+
+func (api *API) EachFile(params *ListFilesParams, opts *PageOptions, fn func(*File) error) error {
+	return api.EachFileContext(context.Background(), params, opts, fn)
+}
+
+func (api *API) EachFileContext(ctx context.Context, params *ListFilesParams, opts *PageOptions, fn func(*File) error) error {
+	p := api.newPagerWithSizeParam("files.list", params.values(), opts, "count")
+
+	var page *filesListResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(filesListResponse)
+		return page
+	}, func(i int) error {
+		return fn(&page.Files[i])
+	})
+}
+
+// UploadFileParams describes a file to be uploaded by UploadFile
+type UploadFileParams struct {
+	Filename string
+
+	// Size is the length of the content in bytes; it may be omitted when the
+	// reader knows its length (e.g., *os.File, *bytes.Reader, *strings.Reader)
+	Size int64
+
+	Title          string
+	AltText        string
+	SnippetType    string
+	ChannelId      string // share to the channel if not empty
+	ThreadTs       string // share as a reply of the thread
+	InitialComment string
+}
+
+type getUploadURLExternalResponse struct {
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+	genericResponse
+}
+
+type completeUploadFile struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+type completeUploadExternalRequest struct {
+	Files          []completeUploadFile `json:"files"`
+	ChannelId      string               `json:"channel_id,omitempty"`
+	ThreadTs       string               `json:"thread_ts,omitempty"`
+	InitialComment string               `json:"initial_comment,omitempty"`
+}
+
+type completeUploadExternalResponse struct {
+	Files []File `json:"files"`
+	genericResponse
+}
+
+// UploadFile uploads content of r using the external upload flow
+// (files.getUploadURLExternal and files.completeUploadExternal); the content
+// is streamed as it is read from r
+func (api *API) UploadFile(r io.Reader, params *UploadFileParams) (*File, error) {
+	return api.UploadFileContext(context.Background(), r, params)
+}
+
+func (api *API) UploadFileContext(ctx context.Context, r io.Reader, params *UploadFileParams) (*File, error) {
+	if params == nil || params.Filename == "" {
+		return nil, fmt.Errorf("empty filename")
+	}
+
+	size := params.Size
+	if size <= 0 {
+		var err error
+
+		size, err = readerSize(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// get upload url
+	vals := make(url.Values)
+	vals.Set("filename", params.Filename)
+	vals.Set("length", strconv.FormatInt(size, 10))
+
+	if params.AltText != "" {
+		vals.Set("alt_txt", params.AltText)
+	}
+
+	if params.SnippetType != "" {
+		vals.Set("snippet_type", params.SnippetType)
+	}
+
+	var urlResp getUploadURLExternalResponse
+
+	err := api.post(ctx, "files.getUploadURLExternal", vals, &urlResp)
+	if err != nil {
+		return nil, err
+	}
+
+	// upload content; the upload url is not a Web API method, so dry-run
+	// skips it here
+	if api.dryRun == nil {
+		err = api.uploadContent(ctx, urlResp.UploadURL, r, size, params.Filename)
+	} else {
+		_, err = io.Copy(ioutil.Discard, r)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// complete upload
+	var completeResp completeUploadExternalResponse
+
+	err = api.postJSON(ctx, "files.completeUploadExternal", completeUploadExternalRequest{
+		Files: []completeUploadFile{{
+			ID:    urlResp.FileID,
+			Title: params.Title,
+		}},
+		ChannelId:      params.ChannelId,
+		ThreadTs:       params.ThreadTs,
+		InitialComment: params.InitialComment,
+	}, &completeResp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(completeResp.Files) < 1 {
+		// never reached
+		return nil, fmt.Errorf("no file was completed")
+	}
+
+	return &completeResp.Files[0], nil
+}
+
+func (api *API) uploadContent(ctx context.Context, uploadURL string, r io.Reader, size int64, filename string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, r)
+	if err != nil {
+		return fmt.Errorf("failed to make upload request: %v", err)
+	}
+
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := api.httpCli.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload file '%s': %w", filename, err)
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload file '%s': status = %s", filename, resp.Status)
+	}
+
+	return nil
+}
+
+func readerSize(r io.Reader) (int64, error) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), nil
+
+	case interface{ Stat() (os.FileInfo, error) }:
+		fi, err := v.Stat()
+		if err != nil {
+			return 0, fmt.Errorf("failed to stat file: %v", err)
+		}
+
+		if fi.Mode().IsRegular() {
+			return fi.Size(), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown size of content; size must be given")
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	const content = "hello, world"
+
+	var ts *httptest.Server
+
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/files.getUploadURLExternal":
+			q := r.URL.Query()
+			if q.Get("filename") != "hello.txt" || q.Get("length") != fmt.Sprint(len(content)) || q.Get("alt_txt") != "greeting" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			fmt.Fprintf(w, `{"ok":true,"upload_url":"%s/upload","file_id":"F1"}`, ts.URL)
+
+		case "/upload":
+			b, _ := ioutil.ReadAll(r.Body)
+			if string(b) != content {
+				t.Errorf("unexpected content: %s", b)
+			}
+
+		case "/api/files.completeUploadExternal":
+			var req completeUploadExternalRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if len(req.Files) != 1 || req.Files[0].ID != "F1" || req.ChannelId != "C1" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"files":[{"id":"F1","permalink":"https://example.slack.com/files/F1"}]}`))
+
+		default:
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	file, err := slack.UploadFile(strings.NewReader(content), &UploadFileParams{
+		Filename:  "hello.txt",
+		AltText:   "greeting",
+		ChannelId: "C1",
+	})
+	if err != nil {
+		t.Fatal("failed to upload file:", err)
+	}
+
+	if file.ID != "F1" || file.Permalink == "" {
+		t.Errorf("unexpected file: %+v", file)
+	}
+}
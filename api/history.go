@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	Type            string           `json:"type"`
+	Channel         string           `json:"channel,omitempty"`
+	Subtype         string           `json:"subtype,omitempty"`
+	User            string           `json:"user,omitempty"`
+	BotID           string           `json:"bot_id,omitempty"`
+	Username        string           `json:"username,omitempty"`
+	Team            string           `json:"team,omitempty"`
+	Text            string           `json:"text"`
+	Ts              string           `json:"ts"`
+	ThreadTs        string           `json:"thread_ts,omitempty"`
+	ParentUserID    string           `json:"parent_user_id,omitempty"`
+	ReplyCount      int              `json:"reply_count,omitempty"`
+	ReplyUsersCount int              `json:"reply_users_count,omitempty"`
+	ReplyUsers      []string         `json:"reply_users,omitempty"`
+	LatestReply     string           `json:"latest_reply,omitempty"`
+	Blocks          json.RawMessage  `json:"blocks,omitempty"`
+	Attachments     json.RawMessage  `json:"attachments,omitempty"`
+	Files           []File           `json:"files,omitempty"`
+	Reactions       []Reaction       `json:"reactions,omitempty"`
+	Edited          *Edited          `json:"edited,omitempty"`
+	Metadata        *MessageMetadata `json:"metadata,omitempty"`
+}
+
+type Reaction struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Users []string `json:"users"`
+}
+
+type Edited struct {
+	User string `json:"user"`
+	Ts   string `json:"ts"`
+}
+
+// Time returns when the message was posted
+func (msg *Message) Time() time.Time {
+	t, _ := TsToTime(msg.Ts)
+	return t
+}
+
+// IsThreadParent reports whether the message starts a thread
+func (msg *Message) IsThreadParent() bool {
+	return msg.ThreadTs != "" && msg.ThreadTs == msg.Ts
+}
+
+// TimeToTs converts t into a Slack timestamp like "1629853200.000100"
+func TimeToTs(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/int(time.Microsecond))
+}
+
+// TsToTime converts a Slack timestamp into time.Time
+func TsToTime(ts string) (time.Time, error) {
+	secs, micros := ts, "0"
+	if i := strings.IndexByte(ts, '.'); i >= 0 {
+		secs, micros = ts[:i], ts[i+1:]
+	}
+
+	// normalize fraction to microseconds
+	if len(micros) > 6 {
+		micros = micros[:6]
+	} else {
+		micros += strings.Repeat("0", 6-len(micros))
+	}
+
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp '%s': %v", ts, err)
+	}
+
+	usec, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp '%s': %v", ts, err)
+	}
+
+	return time.Unix(sec, usec*int64(time.Microsecond)), nil
+}
+
+// HistoryParams filters messages of History and Replies; Oldest and Latest
+// are Slack timestamps (see TimeToTs)
+type HistoryParams struct {
+	Oldest    string
+	Latest    string
+	Inclusive bool
+
+	// IncludeAllMetadata returns metadata of messages posted by other apps too
+	IncludeAllMetadata bool
+}
+
+func (params *HistoryParams) values() url.Values {
+	vals := make(url.Values)
+	if params == nil {
+		return vals
+	}
+
+	if params.Oldest != "" {
+		vals.Set("oldest", params.Oldest)
+	}
+
+	if params.Latest != "" {
+		vals.Set("latest", params.Latest)
+	}
+
+	if params.Inclusive {
+		vals.Set("inclusive", strconv.FormatBool(params.Inclusive))
+	}
+
+	if params.IncludeAllMetadata {
+		vals.Set("include_all_metadata", strconv.FormatBool(params.IncludeAllMetadata))
+	}
+
+	return vals
+}
+
+type historyResponse struct {
+	Messages []Message `json:"messages"`
+	HasMore  bool      `json:"has_more"`
+	genericResponse
+}
+
+func (resp *historyResponse) items() int {
+	return len(resp.Messages)
+}
+
+// History returns messages of the channel, newest first
+func (api *API) History(channelId string, params *HistoryParams, opts *PageOptions) ([]Message, error) {
+	return api.HistoryContext(context.Background(), channelId, params, opts)
+}
+
+func (api *API) HistoryContext(ctx context.Context, channelId string, params *HistoryParams, opts *PageOptions) ([]Message, error) {
+	var messages []Message
+
+	err := api.EachHistoryContext(ctx, channelId, params, opts, func(msg *Message) error {
+		messages = append(messages, *msg)
+		return nil
+	})
+
+	return messages, err
+}
+
+func (api *API) EachHistory(channelId string, params *HistoryParams, opts *PageOptions, fn func(*Message) error) error {
+	return api.EachHistoryContext(context.Background(), channelId, params, opts, fn)
+}
+
+func (api *API) EachHistoryContext(ctx context.Context, channelId string, params *HistoryParams, opts *PageOptions, fn func(*Message) error) error {
+	vals := params.values()
+	vals.Set("channel", channelId)
+
+	return api.eachMessage(ctx, "conversations.history", vals, opts, fn)
+}
+
+// Replies returns messages of the thread, oldest first; the parent message
+// comes first
+func (api *API) Replies(channelId, threadTs string, params *HistoryParams, opts *PageOptions) ([]Message, error) {
+	return api.RepliesContext(context.Background(), channelId, threadTs, params, opts)
+}
+
+func (api *API) RepliesContext(ctx context.Context, channelId, threadTs string, params *HistoryParams, opts *PageOptions) ([]Message, error) {
+	var messages []Message
+
+	err := api.EachReplyContext(ctx, channelId, threadTs, params, opts, func(msg *Message) error {
+		messages = append(messages, *msg)
+		return nil
+	})
+
+	return messages, err
+}
+
+func (api *API) EachReply(channelId, threadTs string, params *HistoryParams, opts *PageOptions, fn func(*Message) error) error {
+	return api.EachReplyContext(context.Background(), channelId, threadTs, params, opts, fn)
+}
+
+func (api *API) EachReplyContext(ctx context.Context, channelId, threadTs string, params *HistoryParams, opts *PageOptions, fn func(*Message) error) error {
+	vals := params.values()
+	vals.Set("channel", channelId)
+	vals.Set("ts", threadTs)
+
+	return api.eachMessage(ctx, "conversations.replies", vals, opts, fn)
+}
+
+func (api *API) eachMessage(ctx context.Context, method string, params url.Values, opts *PageOptions, fn func(*Message) error) error {
+	p := api.newPager(method, params, opts)
+
+	var page *historyResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(historyResponse)
+		return page
+	}, func(i int) error {
+		return fn(&page.Messages[i])
+	})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/conversations.history" || q.Get("channel") != "C1" || q.Get("oldest") != "1629853200.000100" || q.Get("inclusive") != "true" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+
+		w.Write([]byte(`{"ok":true,"messages":[{"type":"message","user":"U1","text":"hi","ts":"1629853200.000200","thread_ts":"1629853200.000200","reply_count":1,"edited":{"user":"U1","ts":"1629853201.000000"},"reactions":[{"name":"+1","count":2,"users":["U1","U2"]}],"blocks":[{"type":"divider"}]}]}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	oldest := time.Unix(1629853200, int64(100*time.Microsecond))
+
+	messages, err := slack.History("C1", &HistoryParams{
+		Oldest:    TimeToTs(oldest),
+		Inclusive: true,
+	}, nil)
+	if err != nil {
+		t.Fatal("failed to get history:", err)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("unexpected messages: %v", messages)
+	}
+
+	msg := messages[0]
+	if !msg.IsThreadParent() || msg.Edited == nil || len(msg.Reactions) != 1 || len(msg.Blocks) == 0 {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if !msg.Time().Equal(time.Unix(1629853200, int64(200*time.Microsecond))) {
+		t.Errorf("unexpected message time: %v", msg.Time())
+	}
+}
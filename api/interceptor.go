@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scryner/util.slack/metrics"
+	"github.com/scryner/util.slack/trace"
+)
+
+// Call is an outbound HTTP request to a Web API method seen by interceptors;
+// retries of a call (by the rate limiter or the retry policy) are seen as
+// separate calls
+type Call struct {
+	Method string      // e.g., "chat.postMessage"
+	Params url.Values  // query or form parameters
+	Body   interface{} // JSON body, nil unless the method takes JSON
+
+	channel string // rate limited per channel if not empty
+}
+
+// Result is the outcome of a call which got a response
+type Result struct {
+	StatusCode int
+	OK         bool   // the "ok" field
+	Error      string // the "error" field, e.g., "channel_not_found"
+	Latency    time.Duration
+
+	resp *http.Response
+}
+
+// Invoker sends the call
+type Invoker func(ctx context.Context, call *Call) (*Result, error)
+
+// Interceptor is invoked for each call and is expected to call next; it may
+// inspect or change the call and the result, or fail the call on its own
+type Interceptor func(ctx context.Context, call *Call, next Invoker) (*Result, error)
+
+// Interceptors adds interceptors to the client; the first one is the
+// outermost
+func Interceptors(interceptors ...Interceptor) Option {
+	return func(api *API) error {
+		api.interceptors = append(api.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// invoke sends the call through the interceptors; the request is built by
+// newReq from the call given to the innermost invoker, so that changes of
+// interceptors to the method, parameters or body take effect
+func (api *API) invoke(ctx context.Context, call *Call, newReq func(call *Call) (*http.Request, error)) (*http.Response, error) {
+	invoker := func(ctx context.Context, call *Call) (*Result, error) {
+		req, err := newReq(call)
+		if err != nil {
+			return nil, err
+		}
+
+		return api.send(req.WithContext(ctx))
+	}
+
+	for i := len(api.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := api.interceptors[i], invoker
+
+		invoker = func(ctx context.Context, call *Call) (*Result, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	result, err := invoker(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil || result.resp == nil {
+		return nil, fmt.Errorf("interceptor of %s returned no response", call.Method)
+	}
+
+	return result.resp, nil
+}
+
+// send does the request and peeks the result from the response body, which
+// remains readable for decodeResponse
+func (api *API) send(req *http.Request) (*Result, error) {
+	started := time.Now()
+
+	resp, err := api.httpCli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	result := &Result{
+		StatusCode: resp.StatusCode,
+		Latency:    time.Since(started),
+		resp:       resp,
+	}
+
+	var gResp genericResponse
+	if json.Unmarshal(b, &gResp) == nil {
+		result.OK = gResp.OK
+		result.Error = gResp.Error
+	}
+
+	return result, nil
+}
+
+// sensitiveParams are redacted by LoggingInterceptor
+var sensitiveParams = map[string]bool{
+	"token":         true,
+	"code":          true,
+	"refresh_token": true,
+	"client_secret": true,
+}
+
+// LoggingInterceptor logs every call by logf in key=value form with the trace
+// id of ctx if any; values of credentials in parameters are redacted and JSON
+// bodies are not logged
+func LoggingInterceptor(logf func(format string, args ...interface{})) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+		result, err := next(ctx, call)
+
+		params := make([]string, 0, len(call.Params))
+		for k := range call.Params {
+			v := call.Params.Get(k)
+			if sensitiveParams[k] {
+				v = "[REDACTED]"
+			}
+
+			params = append(params, fmt.Sprintf("%s=%q", k, v))
+		}
+
+		sort.Strings(params)
+
+		// correlate with the inbound request if traced
+		var traceId string
+		if sc := trace.SpanFromContext(ctx).SpanContext(); sc.IsValid() {
+			traceId = fmt.Sprintf(" trace_id=%s", sc.TraceID)
+		}
+
+		if err != nil {
+			logf("slack api call: method=%s params={%s} err=%q%s", call.Method, strings.Join(params, " "), err.Error(), traceId)
+			return result, err
+		}
+
+		logf("slack api call: method=%s params={%s} status=%d ok=%t error=%q latency=%s%s",
+			call.Method, strings.Join(params, " "), result.StatusCode, result.OK, result.Error, result.Latency, traceId)
+
+		return result, err
+	}
+}
+
+// LatencyInterceptor observes latencies of calls in seconds to hist labeled
+// by method only, including calls which failed to get a response
+func LatencyInterceptor(hist *metrics.Histogram) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+		started := time.Now()
+		result, err := next(ctx, call)
+
+		hist.Observe(time.Since(started).Seconds(), call.Method)
+
+		return result, err
+	}
+}
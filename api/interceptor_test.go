@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/scryner/util.slack/metrics"
+)
+
+func TestInterceptors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer ts.Close()
+
+	var logs []string
+	var order []string
+
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+			order = append(order, name)
+			return next(ctx, call)
+		}
+	}
+
+	reg := metrics.NewRegistry()
+	hist := reg.Histogram("latency_seconds", "Latency.", nil, "method")
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), Interceptors(
+		trace("first"),
+		LoggingInterceptor(func(format string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		}),
+		LatencyInterceptor(hist),
+		trace("last"),
+	))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	_, err = slack.GetConversationInfo("C1")
+	if err == nil {
+		t.Fatal("call must fail")
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "last" {
+		t.Errorf("unexpected order: %v", order)
+	}
+
+	if len(logs) != 1 || !strings.Contains(logs[0], "method=conversations.info") || !strings.Contains(logs[0], `error="channel_not_found"`) {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+
+	var text strings.Builder
+	reg.WriteText(&text)
+
+	if !strings.Contains(text.String(), `latency_seconds_count{method="conversations.info"} 1`) {
+		t.Errorf("latency must be recorded: %s", text.String())
+	}
+}
+
+func TestInterceptorChangingCall(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/conversations.info":
+			if q := r.URL.Query(); q.Get("channel") != "C2" || q.Get("include_locale") != "true" {
+				t.Errorf("changed params must be sent: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":{"id":"C2"}}`))
+
+		case "/api/chat.postMessage":
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req["text"] != "changed" {
+				t.Errorf("changed body must be sent: %v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1.0"}`))
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), Interceptors(
+		func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+			if call.Params != nil {
+				call.Params.Set("channel", "C2")
+				call.Params.Set("include_locale", "true")
+			}
+
+			if call.Body != nil {
+				call.Body = map[string]string{"channel": "C1", "text": "changed"}
+			}
+
+			return next(ctx, call)
+		},
+	))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	conversation, err := slack.GetConversationInfo("C1")
+	if err != nil || conversation.ID != "C2" {
+		t.Errorf("unexpected conversation: %v, %+v", err, conversation)
+	}
+
+	if _, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err != nil {
+		t.Error("failed to post message:", err)
+	}
+}
+
+func TestLoggingInterceptorRedaction(t *testing.T) {
+	var logged string
+
+	interceptor := LoggingInterceptor(func(format string, args ...interface{}) {
+		logged = fmt.Sprintf(format, args...)
+	})
+
+	call := &Call{Method: "oauth.v2.access"}
+	call.Params = map[string][]string{"refresh_token": {"xoxe-1"}}
+
+	interceptor(context.Background(), call, func(ctx context.Context, call *Call) (*Result, error) {
+		return &Result{StatusCode: http.StatusOK, OK: true}, nil
+	})
+
+	if strings.Contains(logged, "xoxe-1") || !strings.Contains(logged, "[REDACTED]") {
+		t.Errorf("refresh token must be redacted: %s", logged)
+	}
+}
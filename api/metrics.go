@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/scryner/util.slack/metrics"
+)
+
+// Metrics records outbound calls to reg: attempts, errors, rate limited
+// responses and latencies per method. Every HTTP request is an attempt, so
+// retries by the rate limiter or the retry policy are counted separately.
+// Clients may share a registry.
+func Metrics(reg *metrics.Registry) Option {
+	return func(api *API) error {
+		attempts := reg.Counter("slack_api_attempts_total", "Slack Web API call attempts including retries.", "method")
+		errs := reg.Counter("slack_api_errors_total", "Failed Slack Web API calls by error.", "method", "error")
+		rateLimited := reg.Counter("slack_api_rate_limited_total", "Slack Web API responses of 429.", "method")
+		latency := reg.Histogram("slack_api_call_duration_seconds", "Latency of Slack Web API calls.", nil, "method")
+
+		api.interceptors = append(api.interceptors, LatencyInterceptor(latency), func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+			attempts.Inc(call.Method)
+
+			result, err := next(ctx, call)
+
+			switch {
+			case err != nil:
+				errs.Inc(call.Method, "request_failed")
+
+			case result == nil:
+				// an inner interceptor answered without a response
+				errs.Inc(call.Method, "no_response")
+
+			case result.StatusCode == http.StatusTooManyRequests:
+				rateLimited.Inc(call.Method)
+
+			case result.StatusCode != http.StatusOK:
+				errs.Inc(call.Method, http.StatusText(result.StatusCode))
+
+			case !result.OK:
+				errs.Inc(call.Method, result.Error)
+			}
+
+			return result, err
+		})
+
+		return nil
+	}
+}
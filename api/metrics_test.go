@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scryner/util.slack/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"ok":false,"error":"message_not_found"}`))
+	}))
+	defer ts.Close()
+
+	reg := metrics.NewRegistry()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), Metrics(reg))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.DeleteMessage("C1", "1.0"); err == nil {
+		t.Fatal("call must fail")
+	}
+
+	var buf bytes.Buffer
+	reg.WriteText(&buf)
+
+	for _, expected := range []string{
+		`slack_api_attempts_total{method="chat.delete"} 2`,
+		`slack_api_rate_limited_total{method="chat.delete"} 1`,
+		`slack_api_errors_total{method="chat.delete",error="message_not_found"} 1`,
+		`slack_api_call_duration_seconds_count{method="chat.delete"} 2`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("'%s' is missing in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestMetricsTransportError(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	slack, err := New("xoxb-test", ServerAddress("http://127.0.0.1:0"), Metrics(reg))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.DeleteMessage("C1", "1.0"); err == nil {
+		t.Fatal("call must fail")
+	}
+
+	var buf bytes.Buffer
+	reg.WriteText(&buf)
+
+	for _, expected := range []string{
+		`slack_api_errors_total{method="chat.delete",error="request_failed"} 1`,
+		`slack_api_call_duration_seconds_count{method="chat.delete"} 1`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("'%s' is missing in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestMetricsNoResult(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	slack, err := New("xoxb-test", ServerAddress("http://127.0.0.1:0"), Metrics(reg), Interceptors(
+		func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+			return nil, nil
+		},
+	))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.DeleteMessage("C1", "1.0"); err == nil {
+		t.Fatal("call must fail")
+	}
+
+	var buf bytes.Buffer
+	reg.WriteText(&buf)
+
+	if expected := `slack_api_errors_total{method="chat.delete",error="no_response"} 1`; !strings.Contains(buf.String(), expected) {
+		t.Errorf("'%s' is missing in:\n%s", expected, buf.String())
+	}
+}
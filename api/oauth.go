@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type OAuthTeam struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type OAuthAuthedUser struct {
+	ID           string `json:"id"`
+	Scope        string `json:"scope"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// OAuthV2Access is the result of oauth.v2.access
+type OAuthV2Access struct {
+	AppID               string          `json:"app_id"`
+	AccessToken         string          `json:"access_token"`
+	TokenType           string          `json:"token_type"`
+	Scope               string          `json:"scope"`
+	BotUserID           string          `json:"bot_user_id"`
+	RefreshToken        string          `json:"refresh_token"` // only with token rotation
+	ExpiresIn           int64           `json:"expires_in"`    // seconds; only with token rotation
+	Team                OAuthTeam       `json:"team"`
+	Enterprise          *OAuthTeam      `json:"enterprise"`
+	IsEnterpriseInstall bool            `json:"is_enterprise_install"`
+	AuthedUser          OAuthAuthedUser `json:"authed_user"`
+}
+
+// ExpiresAt returns when the access token expires, or zero time if it never
+// expires
+func (access *OAuthV2Access) ExpiresAt(issuedAt time.Time) time.Time {
+	if access.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+
+	return issuedAt.Add(time.Duration(access.ExpiresIn) * time.Second)
+}
+
+type oauthV2AccessResponse struct {
+	OAuthV2Access
+	genericResponse
+}
+
+// ExchangeOAuthCode exchanges the code given to the OAuth redirect URL for
+// tokens by oauth.v2.access; opts are applied to the underlying API client
+// (e.g., ServerAddress) except ValidateToken and DryRun
+func ExchangeOAuthCode(ctx context.Context, clientId, clientSecret, code, redirectURI string, opts ...Option) (*OAuthV2Access, error) {
+	params := make(url.Values)
+	params.Set("code", code)
+
+	if redirectURI != "" {
+		params.Set("redirect_uri", redirectURI)
+	}
+
+	return oauthV2Access(ctx, clientId, clientSecret, params, opts...)
+}
+
+// oauthClient overrides options given for oauth.v2.access which do not apply
+// to it: the client has no token to be validated, and exchanging credentials
+// changes nothing in the workspace to be held back by dry-run
+func oauthClient() Option {
+	return func(api *API) error {
+		api.validateToken = false
+		api.requiredScopes = nil
+		api.dryRun = nil
+		return nil
+	}
+}
+
+func oauthV2Access(ctx context.Context, clientId, clientSecret string, params url.Values, opts ...Option) (*OAuthV2Access, error) {
+	api, err := New("", append(opts[:len(opts):len(opts)], oauthClient())...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp oauthV2AccessResponse
+
+	err = api.call(ctx, "oauth.v2.access", &resp, func() (*http.Response, error) {
+		return api.doHTTPPostForm(ctx, "api/oauth.v2.access", params, clientId, clientSecret)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.OAuthV2Access, nil
+}
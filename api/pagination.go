@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 200
+)
+
+// StopIteration can be returned by the callback of EachXXX methods to stop
+// paging without an error
+var StopIteration = errors.New("stop iteration")
+
+// PageOptions controls how list methods page through their results
+type PageOptions struct {
+	// PageSize is the number of items requested per page (i.e., "limit")
+	PageSize int
+
+	// Max limits the total number of items; zero means all
+	Max int
+}
+
+// pageResponse is implemented by responses of list methods; it returns the
+// query parameter and its value to request the next page, or empty strings
+// when it was the last page
+type pageResponse interface {
+	response
+	nextPage() (param, value string)
+}
+
+func (resp *genericResponse) nextPage() (string, string) {
+	return "cursor", resp.ResponseMetadata.NextCursor
+}
+
+// pager walks through pages of a list method. Every page is requested through
+// the usual call path, so pacing and Retry-After of the rate limiter apply to
+// each page and a rate limited page is retried with the same cursor instead of
+// aborting the whole listing.
+type pager struct {
+	api    *API
+	method string
+	params url.Values
+
+	max   int
+	taken int
+	done  bool
+}
+
+func (api *API) newPager(method string, params url.Values, opts *PageOptions) *pager {
+	return api.newPagerWithSizeParam(method, params, opts, "limit")
+}
+
+// newPagerWithSizeParam makes a pager requesting the page size by sizeParam;
+// no page size is requested if sizeParam is empty
+func (api *API) newPagerWithSizeParam(method string, params url.Values, opts *PageOptions, sizeParam string) *pager {
+	pageSize := defaultPageSize
+	max := 0
+
+	if opts != nil {
+		if opts.PageSize > 0 {
+			pageSize = opts.PageSize
+		}
+
+		max = opts.Max
+	}
+
+	if max > 0 && max < pageSize {
+		pageSize = max
+	}
+
+	// copy params not to touch caller's
+	p := make(url.Values)
+	for k, v := range params {
+		p[k] = v
+	}
+
+	if sizeParam != "" {
+		p.Set(sizeParam, strconv.Itoa(pageSize))
+	}
+
+	return &pager{
+		api:    api,
+		method: method,
+		params: p,
+		max:    max,
+	}
+}
+
+// fetch decodes the next page into page; it returns false when there are no
+// more pages or an error occurred
+func (p *pager) fetch(ctx context.Context, page pageResponse) (bool, error) {
+	if p.done || (p.max > 0 && p.taken >= p.max) {
+		return false, nil
+	}
+
+	if err := p.api.get(ctx, p.method, p.params, page); err != nil {
+		p.done = true
+		return false, err
+	}
+
+	param, value := page.nextPage()
+	if value == "" {
+		p.done = true
+	} else {
+		p.params.Set(param, value)
+	}
+
+	return true, nil
+}
+
+// listResponse is a page of a list method which knows the number of its
+// items
+type listResponse interface {
+	pageResponse
+	items() int
+}
+
+// each walks through all pages; every page is decoded into what newPage
+// returns and yield is called with the index of each item of the page until
+// the item limit is reached or yield returns an error
+func (p *pager) each(ctx context.Context, newPage func() listResponse, yield func(i int) error) error {
+	for {
+		page := newPage()
+
+		if ok, err := p.fetch(ctx, page); !ok {
+			return err
+		}
+
+		for i := 0; i < page.items(); i++ {
+			if !p.take() {
+				return nil
+			}
+
+			if err := yield(i); err != nil {
+				return stopped(err)
+			}
+		}
+	}
+}
+
+// take reports whether one more item may be yielded
+func (p *pager) take() bool {
+	if p.max > 0 && p.taken >= p.max {
+		return false
+	}
+
+	p.taken++
+	return true
+}
+
+// stopped turns StopIteration into nil
+func stopped(err error) error {
+	if errors.Is(err, StopIteration) {
+		return nil
+	}
+
+	return err
+}
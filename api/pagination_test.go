@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newUsersListServer(t *testing.T, pages int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/users.list" {
+			t.Errorf("unexpected path '%s'", r.URL.Path)
+		}
+
+		var page int
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			fmt.Sscanf(cursor, "page%d", &page)
+		}
+
+		var next string
+		if page+1 < pages {
+			next = fmt.Sprintf("page%d", page+1)
+		}
+
+		fmt.Fprintf(w, `{"ok":true,"members":[{"id":"U%d0"},{"id":"U%d1"}],"response_metadata":{"next_cursor":"%s"}}`, page, page, next)
+	}))
+}
+
+func TestPagination(t *testing.T) {
+	ts := newUsersListServer(t, 3)
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	// fetch all
+	users, err := slack.ListUsers(nil)
+	if err != nil {
+		t.Fatal("failed to list users:", err)
+	}
+
+	if len(users) != 6 || users[5].ID != "U21" {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	// listed users must be cached
+	if user, err := slack.GetUserInfo("U21"); err != nil || user.ID != "U21" {
+		t.Errorf("listed user must be cached: %v", err)
+	}
+
+	// with limit
+	users, err = slack.ListUsers(&PageOptions{Max: 3})
+	if err != nil {
+		t.Fatal("failed to list users:", err)
+	}
+
+	if len(users) != 3 || users[2].ID != "U10" {
+		t.Errorf("unexpected users: %v", users)
+	}
+
+	// stop streaming
+	var ids []string
+
+	err = slack.EachUser(nil, func(user *User) error {
+		ids = append(ids, user.ID)
+		if user.ID == "U11" {
+			return StopIteration
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Error("StopIteration must not be reported:", err)
+	}
+
+	if len(ids) != 4 {
+		t.Errorf("iteration must be stopped at 4th user: %v", ids)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"net/url"
+)
+
+type PinnedItem struct {
+	Type      string   `json:"type"` // message or file
+	Channel   string   `json:"channel"`
+	Created   int64    `json:"created"`
+	CreatedBy string   `json:"created_by"`
+	Message   *Message `json:"message,omitempty"`
+	File      *File    `json:"file,omitempty"`
+}
+
+type pinRequest struct {
+	ChannelId string `json:"channel"`
+	Timestamp string `json:"timestamp"`
+}
+
+func (api *API) AddPin(channelId, timestamp string) error {
+	return api.AddPinContext(context.Background(), channelId, timestamp)
+}
+
+func (api *API) AddPinContext(ctx context.Context, channelId, timestamp string) error {
+	return api.postJSON(ctx, "pins.add", pinRequest{
+		ChannelId: channelId,
+		Timestamp: timestamp,
+	}, new(genericResponse))
+}
+
+func (api *API) RemovePin(channelId, timestamp string) error {
+	return api.RemovePinContext(context.Background(), channelId, timestamp)
+}
+
+func (api *API) RemovePinContext(ctx context.Context, channelId, timestamp string) error {
+	return api.postJSON(ctx, "pins.remove", pinRequest{
+		ChannelId: channelId,
+		Timestamp: timestamp,
+	}, new(genericResponse))
+}
+
+type listPinsResponse struct {
+	Items []PinnedItem `json:"items"`
+	genericResponse
+}
+
+func (api *API) ListPins(channelId string) ([]PinnedItem, error) {
+	return api.ListPinsContext(context.Background(), channelId)
+}
+
+func (api *API) ListPinsContext(ctx context.Context, channelId string) ([]PinnedItem, error) {
+	params := make(url.Values)
+	params.Set("channel", channelId)
+
+	var resp listPinsResponse
+
+	err := api.get(ctx, "pins.list", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Items, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPins(t *testing.T) {
+	var paths []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/pins.add", "/api/pins.remove":
+			var req pinRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req.ChannelId != "C1" || req.Timestamp != "1.0" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			w.Write([]byte(`{"ok":true}`))
+
+		case "/api/pins.list":
+			if r.URL.Query().Get("channel") != "C1" {
+				t.Errorf("unexpected query: %v", r.URL.Query())
+			}
+
+			w.Write([]byte(`{"ok":true,"items":[{"type":"message","channel":"C1","created_by":"U1","message":{"ts":"1.0","text":"pinned"}}]}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.AddPin("C1", "1.0"); err != nil {
+		t.Fatal("failed to add pin:", err)
+	}
+
+	pins, err := slack.ListPins("C1")
+	if err != nil {
+		t.Fatal("failed to list pins:", err)
+	}
+
+	if len(pins) != 1 || pins[0].Type != "message" || pins[0].Message == nil || pins[0].Message.Text != "pinned" {
+		t.Errorf("unexpected pins: %+v", pins)
+	}
+
+	if err = slack.RemovePin("C1", "1.0"); err != nil {
+		t.Fatal("failed to remove pin:", err)
+	}
+
+	if strings.Join(paths, ",") != "/api/pins.add,/api/pins.list,/api/pins.remove" {
+		t.Errorf("unexpected calls: %v", paths)
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Presence struct {
+	Presence        string `json:"presence"` // active or away
+	Online          bool   `json:"online"`
+	AutoAway        bool   `json:"auto_away"`
+	ManualAway      bool   `json:"manual_away"`
+	ConnectionCount int    `json:"connection_count"`
+	LastActivity    int64  `json:"last_activity"`
+}
+
+func (presence *Presence) IsActive() bool {
+	return presence.Presence == "active"
+}
+
+type presenceResponse struct {
+	Presence
+	genericResponse
+}
+
+func (api *API) GetPresence(userId string) (*Presence, error) {
+	return api.GetPresenceContext(context.Background(), userId)
+}
+
+func (api *API) GetPresenceContext(ctx context.Context, userId string) (*Presence, error) {
+	params := make(url.Values)
+	params.Set("user", userId)
+
+	var resp presenceResponse
+
+	err := api.get(ctx, "users.getPresence", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Presence, nil
+}
+
+type DNDStatus struct {
+	DNDEnabled      bool  `json:"dnd_enabled"`
+	NextDNDStartTs  int64 `json:"next_dnd_start_ts"`
+	NextDNDEndTs    int64 `json:"next_dnd_end_ts"`
+	SnoozeEnabled   bool  `json:"snooze_enabled"`
+	SnoozeEndTime   int64 `json:"snooze_endtime"`
+	SnoozeRemaining int64 `json:"snooze_remaining"`
+}
+
+// Until returns when do-not-disturb ends if it is in effect at t, or zero time
+func (dnd *DNDStatus) Until(t time.Time) time.Time {
+	var until time.Time
+
+	if dnd.SnoozeEnabled && dnd.SnoozeEndTime > t.Unix() {
+		until = time.Unix(dnd.SnoozeEndTime, 0)
+	}
+
+	if dnd.DNDEnabled && dnd.NextDNDStartTs <= t.Unix() && t.Unix() < dnd.NextDNDEndTs {
+		if end := time.Unix(dnd.NextDNDEndTs, 0); end.After(until) {
+			until = end
+		}
+	}
+
+	return until
+}
+
+type dndInfoResponse struct {
+	DNDStatus
+	genericResponse
+}
+
+func (api *API) GetDNDInfo(userId string) (*DNDStatus, error) {
+	return api.GetDNDInfoContext(context.Background(), userId)
+}
+
+func (api *API) GetDNDInfoContext(ctx context.Context, userId string) (*DNDStatus, error) {
+	params := make(url.Values)
+	params.Set("user", userId)
+
+	var resp dndInfoResponse
+
+	err := api.get(ctx, "dnd.info", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.DNDStatus, nil
+}
+
+type dndTeamInfoResponse struct {
+	Users map[string]DNDStatus `json:"users"`
+	genericResponse
+}
+
+// GetDNDTeamInfo returns DND status of up to 50 users keyed by user id; only
+// scheduled DND (not snooze) is reported by Slack
+func (api *API) GetDNDTeamInfo(userIds ...string) (map[string]DNDStatus, error) {
+	return api.GetDNDTeamInfoContext(context.Background(), userIds...)
+}
+
+func (api *API) GetDNDTeamInfoContext(ctx context.Context, userIds ...string) (map[string]DNDStatus, error) {
+	params := make(url.Values)
+	params.Set("users", strings.Join(userIds, ","))
+
+	var resp dndTeamInfoResponse
+
+	err := api.get(ctx, "dnd.teamInfo", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Users, nil
+}
+
+// QuietHours is a daily range of hours in user's local time (e.g., 22 to 8)
+// in which users should not be disturbed even without DND
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+func (quiet *QuietHours) until(t time.Time, tzOffset int) time.Time {
+	if quiet == nil || quiet.Start == quiet.End {
+		return time.Time{}
+	}
+
+	local := t.In(time.FixedZone("", tzOffset))
+	hour := local.Hour()
+
+	var in bool
+	if quiet.Start < quiet.End {
+		in = quiet.Start <= hour && hour < quiet.End
+	} else {
+		// over midnight
+		in = hour >= quiet.Start || hour < quiet.End
+	}
+
+	if !in {
+		return time.Time{}
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), quiet.End, 0, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	return end
+}
+
+// notifyAt returns when a user can be notified; zero time means right now
+func notifyAt(now time.Time, dnd *DNDStatus, tzOffset int, quiet *QuietHours) time.Time {
+	at := now
+
+	// shift as long as DND or quiet hours cover the time
+	for i := 0; i < 3; i++ {
+		until := dnd.Until(at)
+		if quietUntil := quiet.until(at, tzOffset); quietUntil.After(until) {
+			until = quietUntil
+		}
+
+		if !until.After(at) {
+			break
+		}
+
+		at = until
+	}
+
+	if !at.After(now) {
+		return time.Time{}
+	}
+
+	return at
+}
+
+// Notification is the result of NotifyUser
+type Notification struct {
+	ChannelId          string
+	Ts                 string    // set when posted right now
+	ScheduledMessageId string    // set when scheduled
+	PostAt             time.Time // set when scheduled
+}
+
+func (n *Notification) Scheduled() bool {
+	return n.ScheduledMessageId != ""
+}
+
+// NotifyUser sends msg to user as a bot DM right now, or schedules it to the
+// end of user's DND or quiet hours (evaluated by user's TzOffset)
+func (api *API) NotifyUser(user *User, msg *ChatMessage, quiet *QuietHours) (*Notification, error) {
+	return api.NotifyUserContext(context.Background(), user, msg, quiet)
+}
+
+func (api *API) NotifyUserContext(ctx context.Context, user *User, msg *ChatMessage, quiet *QuietHours) (*Notification, error) {
+	dnd, err := api.GetDNDInfoContext(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	at := notifyAt(time.Now(), dnd, user.TzOffset, quiet)
+	if at.IsZero() {
+		channelId, ts, err := api.PostBotDirectMessageContext(ctx, user, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Notification{
+			ChannelId: channelId,
+			Ts:        ts,
+		}, nil
+	}
+
+	// schedule it
+	channelId, err := api.openDMChannel(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := api.ScheduleMessageContext(ctx, channelId, at, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notification{
+		ChannelId:          channelId,
+		ScheduledMessageId: scheduled.ID,
+		PostAt:             at,
+	}, nil
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifyAt(t *testing.T) {
+	// 2021-09-01 12:00 UTC
+	now := time.Date(2021, 9, 1, 12, 0, 0, 0, time.UTC)
+	kst := 9 * 60 * 60
+
+	for i, tc := range []struct {
+		dnd      DNDStatus
+		tzOffset int
+		quiet    *QuietHours
+		expected time.Time
+	}{
+		// nothing blocks
+		{DNDStatus{}, 0, nil, time.Time{}},
+		// in DND until 13:00
+		{DNDStatus{DNDEnabled: true, NextDNDStartTs: now.Add(-time.Hour).Unix(), NextDNDEndTs: now.Add(time.Hour).Unix()}, 0, nil, now.Add(time.Hour)},
+		// DND not started yet
+		{DNDStatus{DNDEnabled: true, NextDNDStartTs: now.Add(time.Hour).Unix(), NextDNDEndTs: now.Add(2 * time.Hour).Unix()}, 0, nil, time.Time{}},
+		// snoozed for 30 minutes
+		{DNDStatus{SnoozeEnabled: true, SnoozeEndTime: now.Add(30 * time.Minute).Unix()}, 0, nil, now.Add(30 * time.Minute)},
+		// 21:00 in KST is not in quiet hours
+		{DNDStatus{}, kst, &QuietHours{Start: 22, End: 8}, time.Time{}},
+		// 21:00 in KST, quiet from 20 to 8 of next day (23:00 UTC)
+		{DNDStatus{}, kst, &QuietHours{Start: 20, End: 8}, time.Date(2021, 9, 1, 23, 0, 0, 0, time.UTC)},
+	} {
+		dnd := tc.dnd
+
+		if at := notifyAt(now, &dnd, tc.tzOffset, tc.quiet); !at.Equal(tc.expected) {
+			t.Errorf("#%d: must be notified at %v, but %v", i, tc.expected, at)
+		}
+	}
+}
+
+func TestPresenceAndDND(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		switch r.URL.Path {
+		case "/api/users.getPresence":
+			if q.Get("user") != "U1" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"presence":"active","online":true,"auto_away":false,"manual_away":false,"connection_count":2,"last_activity":1630000000}`))
+
+		case "/api/dnd.info":
+			if q.Get("user") != "U1" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"dnd_enabled":true,"next_dnd_start_ts":1630000000,"next_dnd_end_ts":1630030000,"snooze_enabled":true,"snooze_endtime":1630003600,"snooze_remaining":1200}`))
+
+		case "/api/dnd.teamInfo":
+			if q.Get("users") != "U1,U2" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"users":{"U1":{"dnd_enabled":true,"next_dnd_start_ts":1630000000,"next_dnd_end_ts":1630030000},"U2":{"dnd_enabled":false}}}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	presence, err := slack.GetPresence("U1")
+	if err != nil {
+		t.Fatal("failed to get presence:", err)
+	}
+
+	if !presence.IsActive() || !presence.Online || presence.ConnectionCount != 2 || presence.LastActivity != 1630000000 {
+		t.Errorf("unexpected presence: %+v", presence)
+	}
+
+	dnd, err := slack.GetDNDInfo("U1")
+	if err != nil {
+		t.Fatal("failed to get DND info:", err)
+	}
+
+	if !dnd.DNDEnabled || dnd.NextDNDEndTs != 1630030000 || !dnd.SnoozeEnabled || dnd.SnoozeEndTime != 1630003600 || dnd.SnoozeRemaining != 1200 {
+		t.Errorf("unexpected DND status: %+v", dnd)
+	}
+
+	team, err := slack.GetDNDTeamInfo("U1", "U2")
+	if err != nil {
+		t.Fatal("failed to get DND team info:", err)
+	}
+
+	if len(team) != 2 || !team["U1"].DNDEnabled || team["U1"].NextDNDStartTs != 1630000000 || team["U2"].DNDEnabled {
+		t.Errorf("unexpected DND team status: %+v", team)
+	}
+}
+
+func TestNotifyUser(t *testing.T) {
+	snoozeEnd := time.Now().Add(time.Hour).Unix()
+
+	var posted, scheduled int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/dnd.info":
+			if r.URL.Query().Get("user") == "U2" {
+				fmt.Fprintf(w, `{"ok":true,"snooze_enabled":true,"snooze_endtime":%d}`, snoozeEnd)
+				return
+			}
+
+			w.Write([]byte(`{"ok":true}`))
+
+		case "/api/conversations.open":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"D2"}}`))
+
+		case "/api/chat.postMessage":
+			atomic.AddInt32(&posted, 1)
+
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req["channel"] != "D1" || req["text"] != "hello" {
+				t.Errorf("unexpected request: %v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":"D1","ts":"1.0","message":{"type":"message","text":"hello","ts":"1.0"}}`))
+
+		case "/api/chat.scheduleMessage":
+			atomic.AddInt32(&scheduled, 1)
+
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req["channel"] != "D2" || req["post_at"] != float64(snoozeEnd) || req["text"] != "hello" {
+				t.Errorf("unexpected request: %v", req)
+			}
+
+			fmt.Fprintf(w, `{"ok":true,"channel":"D2","scheduled_message_id":"Q1","post_at":%d}`, snoozeEnd)
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	// not in DND; posted right now
+	n, err := slack.NotifyUser(&User{ID: "U1", DMChannel: "D1"}, &ChatMessage{Text: "hello"}, nil)
+	if err != nil {
+		t.Fatal("failed to notify user:", err)
+	}
+
+	if n.Scheduled() || n.ChannelId != "D1" || n.Ts != "1.0" {
+		t.Errorf("notification must be posted: %+v", n)
+	}
+
+	// snoozed; scheduled to the end of snooze in the DM channel just opened
+	n, err = slack.NotifyUser(&User{ID: "U2"}, &ChatMessage{Text: "hello"}, nil)
+	if err != nil {
+		t.Fatal("failed to notify user:", err)
+	}
+
+	if !n.Scheduled() || n.ChannelId != "D2" || n.ScheduledMessageId != "Q1" || n.PostAt.Unix() != snoozeEnd {
+		t.Errorf("notification must be scheduled: %+v", n)
+	}
+
+	if posted != 1 || scheduled != 1 {
+		t.Errorf("message must be posted once and scheduled once, but %d and %d", posted, scheduled)
+	}
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Tier is a rate limit tier of Slack Web API methods
+// (see https://api.slack.com/docs/rate-limits)
+type Tier int
+
+const (
+	Tier1 Tier = iota + 1 // 1+ per minute
+	Tier2                 // 20+ per minute
+	Tier3                 // 50+ per minute
+	Tier4                 // 100+ per minute
+)
+
+func (tier Tier) perMinute() int {
+	switch tier {
+	case Tier1:
+		return 1
+	case Tier2:
+		return 20
+	case Tier3:
+		return 50
+	case Tier4:
+		return 100
+	default:
+		return 0
+	}
+}
+
+const (
+	defaultRateLimitRetries = 3
+	defaultRetryAfter       = time.Second
+
+	// chat.postMessage is limited to 1 message per second per channel
+	postMessagePerChannelInterval = time.Second
+
+	// idle buckets (e.g., of channels posted long ago) are swept this often
+	bucketSweepInterval = time.Minute
+)
+
+var methodTiers = map[string]Tier{
+	"auth.test":                    Tier4,
+	"chat.postEphemeral":           Tier4,
+	"chat.update":                  Tier3,
+	"chat.delete":                  Tier3,
+	"chat.getPermalink":            Tier4,
+	"chat.unfurl":                  Tier3,
+	"chat.scheduleMessage":         Tier3,
+	"chat.scheduledMessages.list":  Tier3,
+	"chat.deleteScheduledMessage":  Tier3,
+	"conversations.create":         Tier2,
+	"conversations.info":           Tier3,
+	"conversations.join":           Tier3,
+	"conversations.leave":          Tier3,
+	"conversations.invite":         Tier3,
+	"conversations.kick":           Tier3,
+	"conversations.archive":        Tier2,
+	"conversations.unarchive":      Tier2,
+	"conversations.rename":         Tier2,
+	"conversations.setTopic":       Tier2,
+	"conversations.setPurpose":     Tier2,
+	"conversations.open":           Tier3,
+	"conversations.list":           Tier2,
+	"conversations.members":        Tier4,
+	"conversations.history":        Tier3,
+	"conversations.replies":        Tier3,
+	"bookmarks.add":                Tier2,
+	"bookmarks.edit":               Tier2,
+	"bookmarks.remove":             Tier2,
+	"bookmarks.list":               Tier2,
+	"dnd.info":                     Tier3,
+	"dnd.teamInfo":                 Tier2,
+	"files.getUploadURLExternal":   Tier4,
+	"files.completeUploadExternal": Tier4,
+	"files.list":                   Tier3,
+	"oauth.v2.access":              Tier4,
+	"pins.add":                     Tier2,
+	"pins.remove":                  Tier2,
+	"pins.list":                    Tier2,
+	"reactions.add":                Tier3,
+	"reactions.remove":             Tier2,
+	"reactions.get":                Tier3,
+	"team.info":                    Tier3,
+	"usergroups.list":              Tier2,
+	"usergroups.create":            Tier2,
+	"usergroups.update":            Tier2,
+	"usergroups.enable":            Tier2,
+	"usergroups.disable":           Tier2,
+	"usergroups.users.list":        Tier2,
+	"usergroups.users.update":      Tier2,
+	"users.profile.get":            Tier4,
+	"users.profile.set":            Tier3,
+	"users.getPresence":            Tier3,
+	"users.list":                   Tier2,
+	"users.info":                   Tier4,
+	"users.lookupByEmail":          Tier3,
+	"views.open":                   Tier4,
+	"views.update":                 Tier4,
+	"views.push":                   Tier4,
+	"views.publish":                Tier4,
+}
+
+// RateLimitConfig configures how rate limits of Slack are handled
+type RateLimitConfig struct {
+	// MaxRetries is the number of times a call answered with HTTP 429 is retried
+	// after waiting for Retry-After; zero disables retries
+	MaxRetries int
+
+	// MaxRetryAfter gives up retrying when Slack asks to wait longer than it;
+	// zero means no bound
+	MaxRetryAfter time.Duration
+
+	// Pacing proactively spaces out calls according to the tier of each method
+	// and the 1 message/sec/channel rule of chat.postMessage
+	Pacing bool
+
+	// Tiers overrides (or adds) the tier of methods, e.g. "users.list": Tier2
+	Tiers map[string]Tier
+}
+
+var defaultRateLimitConfig = RateLimitConfig{
+	MaxRetries: defaultRateLimitRetries,
+}
+
+type rateLimiter struct {
+	config RateLimitConfig
+
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	lock      *sync.Mutex
+}
+
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		buckets: make(map[string]*bucket),
+		lock:    new(sync.Mutex),
+	}
+}
+
+func (limiter *rateLimiter) tier(method string) Tier {
+	if tier, ok := limiter.config.Tiers[method]; ok {
+		return tier
+	}
+
+	if tier, ok := methodTiers[method]; ok {
+		return tier
+	}
+
+	return Tier3
+}
+
+func (limiter *rateLimiter) bucket(method, channel string) *bucket {
+	key := method
+	if method == "chat.postMessage" {
+		key = method + "/" + channel
+	}
+
+	b, ok := limiter.buckets[key]
+	if ok {
+		return b
+	}
+
+	b = new(bucket)
+
+	if limiter.config.Pacing {
+		if method == "chat.postMessage" {
+			b.interval = postMessagePerChannelInterval
+			b.burst = 1
+		} else if perMinute := limiter.tier(method).perMinute(); perMinute > 0 {
+			b.interval = time.Minute / time.Duration(perMinute)
+			b.burst = perMinute
+		}
+	}
+
+	limiter.buckets[key] = b
+	return b
+}
+
+// wait blocks until the call is allowed to be sent
+func (limiter *rateLimiter) wait(ctx context.Context, method, channel string) error {
+	now := time.Now()
+
+	limiter.lock.Lock()
+	delay := limiter.bucket(method, channel).reserve(now)
+
+	if now.Sub(limiter.lastSweep) >= bucketSweepInterval {
+		limiter.sweep(now)
+	}
+	limiter.lock.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// sweep drops buckets which are idle at now; an idle bucket behaves the same as
+// a new one, so nothing is lost
+func (limiter *rateLimiter) sweep(now time.Time) {
+	for key, b := range limiter.buckets {
+		if b.idle(now) {
+			delete(limiter.buckets, key)
+		}
+	}
+
+	limiter.lastSweep = now
+}
+
+// limited records that the call was rate limited and reports whether it
+// should be retried
+func (limiter *rateLimiter) limited(method, channel string, retryAfter time.Duration, retries int) bool {
+	limiter.lock.Lock()
+	limiter.bucket(method, channel).block(time.Now().Add(retryAfter))
+	limiter.lock.Unlock()
+
+	if retries >= limiter.config.MaxRetries {
+		return false
+	}
+
+	if limiter.config.MaxRetryAfter > 0 && retryAfter > limiter.config.MaxRetryAfter {
+		return false
+	}
+
+	return true
+}
+
+// bucket is a token bucket expressed as a theoretical arrival time
+type bucket struct {
+	interval time.Duration
+	burst    int
+
+	tat          time.Time
+	blockedUntil time.Time
+}
+
+// reserve takes a token and returns how long the caller has to wait for it
+func (b *bucket) reserve(now time.Time) time.Duration {
+	var delay time.Duration
+
+	if b.interval > 0 {
+		if b.tat.Before(now) {
+			b.tat = now
+		}
+
+		delay = b.tat.Sub(now) - time.Duration(b.burst-1)*b.interval
+		b.tat = b.tat.Add(b.interval)
+
+		if delay < 0 {
+			delay = 0
+		}
+	}
+
+	if blocked := b.blockedUntil.Sub(now); blocked > delay {
+		delay = blocked
+	}
+
+	return delay
+}
+
+func (b *bucket) idle(now time.Time) bool {
+	return !b.tat.After(now) && !b.blockedUntil.After(now)
+}
+
+func (b *bucket) block(until time.Time) {
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	secs, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return defaultRetryAfter
+	}
+
+	return time.Duration(secs) * time.Second
+}
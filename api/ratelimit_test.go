@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1.0"}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	started := time.Now()
+
+	_, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"})
+	if err != nil {
+		t.Fatal("failed to post message:", err)
+	}
+
+	if calls != 2 {
+		t.Errorf("server must be called twice, but %d", calls)
+	}
+
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Errorf("Retry-After was not honoured: elapsed %v", elapsed)
+	}
+}
+
+func TestRateLimitGiveUp(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), RateLimit(RateLimitConfig{
+		MaxRetries:    3,
+		MaxRetryAfter: time.Second,
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	_, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"})
+	if err == nil {
+		t.Error("rate limited call must fail")
+	}
+}
+
+func TestBucketPacing(t *testing.T) {
+	now := time.Now()
+
+	b := &bucket{
+		interval: time.Second,
+		burst:    2,
+	}
+
+	for i, expected := range []time.Duration{0, 0, time.Second, 2 * time.Second} {
+		if delay := b.reserve(now); delay != expected {
+			t.Errorf("#%d: delay must be %v, but %v", i, expected, delay)
+		}
+	}
+
+	// blocked by Retry-After
+	b = &bucket{}
+	b.block(now.Add(5 * time.Second))
+
+	if delay := b.reserve(now); delay != 5*time.Second {
+		t.Errorf("delay must be 5s, but %v", delay)
+	}
+}
+
+func TestBucketSweep(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{Pacing: true})
+	now := time.Now()
+
+	for _, channel := range []string{"C1", "C2", "C3"} {
+		limiter.bucket("chat.postMessage", channel).reserve(now)
+	}
+
+	limiter.bucket("chat.update", "").block(now.Add(time.Hour))
+
+	limiter.sweep(now.Add(2 * time.Second))
+
+	if len(limiter.buckets) != 1 {
+		t.Errorf("only the blocked bucket must remain, but %d", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+type reactionRequest struct {
+	ChannelId string `json:"channel"`
+	Name      string `json:"name"`
+	Timestamp string `json:"timestamp"`
+}
+
+// emojiName strips colons of emoji like ":blush:" given by RandEmoji
+func emojiName(emoji string) string {
+	return strings.Trim(emoji, ":")
+}
+
+func (api *API) AddReaction(channelId, timestamp, emoji string) error {
+	return api.AddReactionContext(context.Background(), channelId, timestamp, emoji)
+}
+
+func (api *API) AddReactionContext(ctx context.Context, channelId, timestamp, emoji string) error {
+	return api.postJSON(ctx, "reactions.add", reactionRequest{
+		ChannelId: channelId,
+		Name:      emojiName(emoji),
+		Timestamp: timestamp,
+	}, new(genericResponse))
+}
+
+// AddRandomReaction reacts to the message with an emoji picked by RandEmoji
+// and returns it
+func (api *API) AddRandomReaction(channelId, timestamp string) (string, error) {
+	return api.AddRandomReactionContext(context.Background(), channelId, timestamp)
+}
+
+func (api *API) AddRandomReactionContext(ctx context.Context, channelId, timestamp string) (string, error) {
+	emoji := RandEmoji()
+
+	if err := api.AddReactionContext(ctx, channelId, timestamp, emoji); err != nil {
+		return "", err
+	}
+
+	return emoji, nil
+}
+
+func (api *API) RemoveReaction(channelId, timestamp, emoji string) error {
+	return api.RemoveReactionContext(context.Background(), channelId, timestamp, emoji)
+}
+
+func (api *API) RemoveReactionContext(ctx context.Context, channelId, timestamp, emoji string) error {
+	return api.postJSON(ctx, "reactions.remove", reactionRequest{
+		ChannelId: channelId,
+		Name:      emojiName(emoji),
+		Timestamp: timestamp,
+	}, new(genericResponse))
+}
+
+type getReactionsResponse struct {
+	Message Message `json:"message"`
+	genericResponse
+}
+
+func (api *API) GetReactions(channelId, timestamp string) ([]Reaction, error) {
+	return api.GetReactionsContext(context.Background(), channelId, timestamp)
+}
+
+func (api *API) GetReactionsContext(ctx context.Context, channelId, timestamp string) ([]Reaction, error) {
+	params := make(url.Values)
+	params.Set("channel", channelId)
+	params.Set("timestamp", timestamp)
+	params.Set("full", "true")
+
+	var resp getReactionsResponse
+
+	err := api.get(ctx, "reactions.get", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Message.Reactions, nil
+}
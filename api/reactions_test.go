@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReactions(t *testing.T) {
+	var names []string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/reactions.add", "/api/reactions.remove":
+			var req reactionRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req.ChannelId != "C1" || req.Timestamp != "1.0" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			names = append(names, req.Name)
+			w.Write([]byte(`{"ok":true}`))
+
+		case "/api/reactions.get":
+			q := r.URL.Query()
+			if q.Get("channel") != "C1" || q.Get("timestamp") != "1.0" || q.Get("full") != "true" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"type":"message","message":{"ts":"1.0","reactions":[{"name":"blush","count":1,"users":["U1"]}]}}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.AddReaction("C1", "1.0", ":blush:"); err != nil {
+		t.Fatal("failed to add reaction:", err)
+	}
+
+	emoji, err := slack.AddRandomReaction("C1", "1.0")
+	if err != nil {
+		t.Fatal("failed to add random reaction:", err)
+	}
+
+	if err = slack.RemoveReaction("C1", "1.0", "blush"); err != nil {
+		t.Fatal("failed to remove reaction:", err)
+	}
+
+	// colons of emoji are stripped
+	if len(names) != 3 || names[0] != "blush" || names[1] != strings.Trim(emoji, ":") || names[2] != "blush" {
+		t.Errorf("unexpected reactions: %v (random %s)", names, emoji)
+	}
+
+	if strings.Contains(names[1], ":") || names[1] == "" {
+		t.Errorf("unexpected random reaction: %q", names[1])
+	}
+
+	reactions, err := slack.GetReactions("C1", "1.0")
+	if err != nil {
+		t.Fatal("failed to get reactions:", err)
+	}
+
+	if len(reactions) != 1 || reactions[0].Name != "blush" || reactions[0].Count != 1 {
+		t.Errorf("unexpected reactions: %+v", reactions)
+	}
+}
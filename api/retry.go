@@ -0,0 +1,196 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 500 * time.Millisecond
+	defaultRetryMaxDelay    = 30 * time.Second
+	defaultRetryJitter      = 0.5
+)
+
+// nonIdempotentMethods are not retried unless the request surely did not reach
+// Slack, since retrying them may cause duplicates (e.g., double-posting)
+var nonIdempotentMethods = map[string]bool{
+	"chat.postMessage":     true,
+	"chat.postEphemeral":   true,
+	"chat.scheduleMessage": true,
+
+	"bookmarks.add":                true,
+	"files.completeUploadExternal": true,
+	"oauth.v2.access":              true,
+}
+
+// Attempt describes a finished attempt of an API call
+type Attempt struct {
+	Method string
+	Number int           // 1 for the first attempt
+	Err    error         // nil when the attempt succeeded
+	Retry  bool          // whether the call will be retried
+	Delay  time.Duration // backoff before the next attempt
+}
+
+// RetryPolicy retries API calls failed by transient errors with exponential
+// backoff
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one
+	MaxAttempts int
+
+	// BaseDelay is the backoff before the second attempt; it doubles for
+	// every following attempt up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter is the fraction (0 to 1) of the backoff to be randomized
+	Jitter float64
+
+	// Retryable decides whether err is worth retrying; IsTransient is used
+	// when it is nil
+	Retryable func(method string, err error) bool
+
+	// RetryNonIdempotent allows methods like chat.postMessage to be retried
+	// even when Slack might have processed the failed attempt
+	RetryNonIdempotent bool
+
+	// OnAttempt is called after every attempt
+	OnAttempt func(Attempt)
+}
+
+func (policy RetryPolicy) withDefaults() *RetryPolicy {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryMaxAttempts
+	}
+
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultRetryBaseDelay
+	}
+
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultRetryMaxDelay
+	}
+
+	if policy.Jitter < 0 {
+		policy.Jitter = 0
+	} else if policy.Jitter > 1 {
+		policy.Jitter = 1
+	}
+
+	if policy.Retryable == nil {
+		policy.Retryable = func(_ string, err error) bool {
+			return IsTransient(err)
+		}
+	}
+
+	return &policy
+}
+
+// next reports whether the call should be attempted again and how long to
+// wait before it; a nil policy never retries
+func (policy *RetryPolicy) next(ctx context.Context, method string, attempt int, err error) (time.Duration, bool) {
+	if policy == nil {
+		return 0, false
+	}
+
+	var (
+		delay time.Duration
+		retry bool
+	)
+
+	if err != nil && attempt < policy.MaxAttempts && ctx.Err() == nil && policy.Retryable(method, err) {
+		retry = policy.RetryNonIdempotent || !nonIdempotentMethods[method] || notSent(err)
+	}
+
+	if retry {
+		delay = policy.backoff(attempt)
+	}
+
+	if policy.OnAttempt != nil {
+		policy.OnAttempt(Attempt{
+			Method: method,
+			Number: attempt,
+			Err:    err,
+			Retry:  retry,
+			Delay:  delay,
+		})
+	}
+
+	return delay, retry
+}
+
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	if policy.Jitter > 0 {
+		jitter := time.Duration(float64(delay) * policy.Jitter)
+		delay = delay - jitter + time.Duration(rand.Int63n(int64(jitter)+1))
+	}
+
+	return delay
+}
+
+// IsTransient reports whether err is a network error, a 5xx response or one
+// of Slack's internal_error, fatal_error, service_unavailable and
+// request_timeout
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode >= http.StatusInternalServerError {
+			return true
+		}
+
+		switch apiErr.Code {
+		case "internal_error", "fatal_error", "service_unavailable", "request_timeout":
+			return true
+		}
+
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// notSent reports whether err guarantees the request was never delivered
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlakyServer(failures int32) (*httptest.Server, *int32) {
+	var calls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.Write([]byte(`{"ok":false,"error":"internal_error"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1.0"}`))
+	}))
+
+	return ts, &calls
+}
+
+func TestRetry(t *testing.T) {
+	ts, calls := newFlakyServer(2)
+	defer ts.Close()
+
+	var attempts []Attempt
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), Retry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnAttempt: func(attempt Attempt) {
+			attempts = append(attempts, attempt)
+		},
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if err = slack.DeleteMessage("C1", "1.0"); err != nil {
+		t.Fatal("failed to delete message:", err)
+	}
+
+	if *calls != 3 || len(attempts) != 3 {
+		t.Errorf("call must be attempted 3 times, but %d (%d observed)", *calls, len(attempts))
+	}
+
+	if attempts[2].Err != nil || attempts[2].Retry {
+		t.Errorf("last attempt must succeed: %+v", attempts[2])
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	ts, calls := newFlakyServer(1)
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL), Retry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	if _, err = slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err == nil {
+		t.Error("chat.postMessage must not be retried")
+	}
+
+	if *calls != 1 {
+		t.Errorf("chat.postMessage must be attempted once, but %d", *calls)
+	}
+}
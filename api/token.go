@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRefreshBefore  = 5 * time.Minute
+	defaultRefreshTimeout = 30 * time.Second
+	refreshRetryInterval  = 30 * time.Second
+)
+
+// TokenSource provides the access token put on every request
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticToken string
+
+func (token staticToken) Token(context.Context) (string, error) {
+	return string(token), nil
+}
+
+// StaticToken returns a TokenSource of a token that never changes
+func StaticToken(token string) TokenSource {
+	return staticToken(token)
+}
+
+// Token is an access token with its refresh token by token rotation
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time // zero means it never expires
+}
+
+func (token *Token) expiresWithin(now time.Time, d time.Duration) bool {
+	return !token.ExpiresAt.IsZero() && !now.Add(d).Before(token.ExpiresAt)
+}
+
+// RotatingTokenSource refreshes the token by oauth.v2.access with its refresh
+// token before it expires. It is safe for concurrent use; concurrent callers
+// wait for a single refresh (or until their contexts are done) without
+// holding the lock across the network call.
+type RotatingTokenSource struct {
+	clientId       string
+	clientSecret   string
+	refreshBefore  time.Duration
+	refreshTimeout time.Duration
+	onRefresh      func(ctx context.Context, token Token) error
+	onPersistErr   func(token Token, err error)
+	apiOpts        []Option
+
+	token      Token
+	refreshing *tokenRefresh // refresh in flight
+	retryAt    time.Time     // when a failed refresh is tried again
+	lock       *sync.Mutex
+}
+
+type tokenRefresh struct {
+	done  chan struct{} // closed when token and err are set
+	token Token
+	err   error
+}
+
+type TokenSourceOption func(src *RotatingTokenSource)
+
+// RefreshBefore sets how long before the expiry the token is refreshed
+// (default 5 minutes)
+func RefreshBefore(d time.Duration) TokenSourceOption {
+	return func(src *RotatingTokenSource) {
+		src.refreshBefore = d
+	}
+}
+
+// RefreshTimeout bounds a refresh including onRefresh (default 30 seconds);
+// a refresh is not cancelled by the contexts of callers waiting for it
+func RefreshTimeout(timeout time.Duration) TokenSourceOption {
+	return func(src *RotatingTokenSource) {
+		src.refreshTimeout = timeout
+	}
+}
+
+// PersistErrorHandler sets the handler called when onRefresh fails (default
+// logging by the standard logger). The refreshed token is used anyway since
+// the previous one is no longer valid, so the failure does not fail Token.
+func PersistErrorHandler(handler func(token Token, err error)) TokenSourceOption {
+	return func(src *RotatingTokenSource) {
+		src.onPersistErr = handler
+	}
+}
+
+// RefreshAPIOptions are applied to the client calling oauth.v2.access except
+// ValidateToken and DryRun
+func RefreshAPIOptions(opts ...Option) TokenSourceOption {
+	return func(src *RotatingTokenSource) {
+		src.apiOpts = opts
+	}
+}
+
+// NewRotatingTokenSource makes a token source starting from token; onRefresh
+// is called with every new token pair to persist it, since the previous
+// refresh token is no longer valid once refreshed.
+func NewRotatingTokenSource(clientId, clientSecret string, token Token, onRefresh func(ctx context.Context, token Token) error, opts ...TokenSourceOption) *RotatingTokenSource {
+	src := &RotatingTokenSource{
+		clientId:       clientId,
+		clientSecret:   clientSecret,
+		refreshBefore:  defaultRefreshBefore,
+		refreshTimeout: defaultRefreshTimeout,
+		onRefresh:      onRefresh,
+		onPersistErr:   logPersistError,
+		token:          token,
+		lock:           new(sync.Mutex),
+	}
+
+	for _, opt := range opts {
+		opt(src)
+	}
+
+	return src
+}
+
+func logPersistError(token Token, err error) {
+	log.Printf("failed to persist refreshed slack token (expires at %s): %v", token.ExpiresAt.Format(time.RFC3339), err)
+}
+
+func (src *RotatingTokenSource) Token(ctx context.Context) (string, error) {
+	src.lock.Lock()
+
+	if src.usable(time.Now()) {
+		accessToken := src.token.AccessToken
+		src.lock.Unlock()
+
+		return accessToken, nil
+	}
+
+	call := src.refreshing
+	if call == nil {
+		// the refresh is shared by every caller, so it is detached from ctx
+		call = &tokenRefresh{done: make(chan struct{})}
+		src.refreshing = call
+
+		go src.refresh(detachedContext{ctx}, src.token, call)
+	}
+
+	src.lock.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	if call.err != nil {
+		// the current token is still good until it actually expires
+		if current := src.Current(); current.AccessToken != "" && !current.expiresWithin(time.Now(), 0) {
+			return current.AccessToken, nil
+		}
+
+		return "", call.err
+	}
+
+	return call.token.AccessToken, nil
+}
+
+// usable reports whether the current token can be handed out without waiting
+// for a refresh; it must be called with the lock held
+func (src *RotatingTokenSource) usable(now time.Time) bool {
+	token := src.token
+
+	if token.AccessToken == "" || token.expiresWithin(now, 0) {
+		return false
+	}
+
+	// a failed refresh is not retried right away while the token is valid
+	return !token.expiresWithin(now, src.refreshBefore) || now.Before(src.retryAt)
+}
+
+// Current returns the current token pair without refreshing it
+func (src *RotatingTokenSource) Current() Token {
+	src.lock.Lock()
+	defer src.lock.Unlock()
+
+	return src.token
+}
+
+// refresh runs call within the refresh timeout and releases its waiters
+func (src *RotatingTokenSource) refresh(ctx context.Context, current Token, call *tokenRefresh) {
+	ctx, cancel := context.WithTimeout(ctx, src.refreshTimeout)
+	defer cancel()
+
+	call.token, call.err = src.exchange(ctx, current)
+
+	src.lock.Lock()
+	src.refreshing = nil
+	if call.err != nil {
+		src.retryAt = time.Now().Add(refreshRetryInterval)
+	}
+	src.lock.Unlock()
+
+	close(call.done)
+}
+
+// exchange exchanges the refresh token of current for a new token pair, which
+// replaces the token of src and is persisted by onRefresh before waiters of
+// the refresh are released
+func (src *RotatingTokenSource) exchange(ctx context.Context, current Token) (Token, error) {
+	if current.RefreshToken == "" {
+		return Token{}, errors.New("failed to refresh token: empty refresh token")
+	}
+
+	params := make(url.Values)
+	params.Set("grant_type", "refresh_token")
+	params.Set("refresh_token", current.RefreshToken)
+
+	issuedAt := time.Now()
+
+	access, err := oauthV2Access(ctx, src.clientId, src.clientSecret, params, src.apiOpts...)
+	if err != nil {
+		return Token{}, err
+	}
+
+	token := Token{
+		AccessToken:  access.AccessToken,
+		RefreshToken: access.RefreshToken,
+		ExpiresAt:    access.ExpiresAt(issuedAt),
+	}
+
+	if token.RefreshToken == "" {
+		// keep using the current one
+		token.RefreshToken = current.RefreshToken
+	}
+
+	src.lock.Lock()
+	src.token = token
+	src.lock.Unlock()
+
+	if src.onRefresh != nil {
+		if err = src.onRefresh(ctx, token); err != nil && src.onPersistErr != nil {
+			src.onPersistErr(token, err)
+		}
+	}
+
+	return token, nil
+}
+
+// detachedContext carries values of the context without its deadline and
+// cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRotatingTokenSource(t *testing.T) {
+	var refreshes int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/oauth.v2.access":
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "xoxe-1" {
+				t.Errorf("unexpected refresh request: %v", r.Form)
+			}
+
+			n := atomic.AddInt32(&refreshes, 1)
+			fmt.Fprintf(w, `{"ok":true,"access_token":"xoxe.xoxb-%d","refresh_token":"xoxe-%d","expires_in":43200}`, n+1, n+1)
+
+		default:
+			if auth := r.Header.Get("Authorization"); auth != "Bearer xoxe.xoxb-2" {
+				t.Errorf("unexpected authorization: %s", auth)
+			}
+
+			w.Write([]byte(`{"ok":true,"channel":"C1","ts":"1.0"}`))
+		}
+	}))
+	defer ts.Close()
+
+	var persisted Token
+
+	src := NewRotatingTokenSource("client", "secret", Token{
+		AccessToken:  "xoxe.xoxb-1",
+		RefreshToken: "xoxe-1",
+		ExpiresAt:    time.Now().Add(time.Minute), // within refresh margin
+	}, func(ctx context.Context, token Token) error {
+		persisted = token
+		return nil
+	}, RefreshAPIOptions(ServerAddress(ts.URL)))
+
+	slack, err := New("", ServerAddress(ts.URL), BotTokenSource(src))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, err := slack.PostMessage("C1", &ChatMessage{Text: "hello"}); err != nil {
+				t.Error("failed to post message:", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if refreshes != 1 {
+		t.Errorf("token must be refreshed once, but %d", refreshes)
+	}
+
+	if persisted.RefreshToken != "xoxe-2" || persisted.ExpiresAt.Before(time.Now().Add(11*time.Hour)) {
+		t.Errorf("unexpected persisted token: %+v", persisted)
+	}
+}
+
+func TestRotatingTokenSourcePersistError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"access_token":"xoxe.xoxb-2","refresh_token":"xoxe-2","expires_in":43200}`))
+	}))
+	defer ts.Close()
+
+	var failed Token
+
+	src := NewRotatingTokenSource("client", "secret", Token{RefreshToken: "xoxe-1"}, func(ctx context.Context, token Token) error {
+		return fmt.Errorf("store is down")
+	}, RefreshAPIOptions(ServerAddress(ts.URL)), PersistErrorHandler(func(token Token, err error) {
+		failed = token
+	}))
+
+	// the refreshed token is used even though persisting it failed
+	token, err := src.Token(context.Background())
+	if err != nil || token != "xoxe.xoxb-2" {
+		t.Fatalf("refreshed token must be returned: %v, %s", err, token)
+	}
+
+	if failed.RefreshToken != "xoxe-2" || src.Current().RefreshToken != "xoxe-2" {
+		t.Errorf("persist error must be handled with the new token: %+v", failed)
+	}
+}
+
+func TestRotatingTokenSourceRefreshFailure(t *testing.T) {
+	var refreshes int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshes, 1)
+		w.Write([]byte(`{"ok":false,"error":"internal_error"}`))
+	}))
+	defer ts.Close()
+
+	src := NewRotatingTokenSource("client", "secret", Token{
+		AccessToken:  "xoxe.xoxb-1",
+		RefreshToken: "xoxe-1",
+		ExpiresAt:    time.Now().Add(time.Minute), // within refresh margin
+	}, nil, RefreshAPIOptions(ServerAddress(ts.URL)))
+
+	// the current token is good until it expires
+	for i := 0; i < 2; i++ {
+		token, err := src.Token(context.Background())
+		if err != nil || token != "xoxe.xoxb-1" {
+			t.Fatalf("#%d: current token must be returned: %v, %s", i, err, token)
+		}
+	}
+
+	if refreshes != 1 {
+		t.Errorf("failed refresh must not be retried right away, but %d", refreshes)
+	}
+
+	src = NewRotatingTokenSource("client", "secret", Token{
+		AccessToken:  "xoxe.xoxb-1",
+		RefreshToken: "xoxe-1",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}, nil, RefreshAPIOptions(ServerAddress(ts.URL)))
+
+	if _, err := src.Token(context.Background()); err == nil {
+		t.Error("expired token must not be returned")
+	}
+}
+
+func TestRotatingTokenSourceDetachedRefresh(t *testing.T) {
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"ok":true,"access_token":"xoxe.xoxb-2","refresh_token":"xoxe-2","expires_in":43200}`))
+	}))
+	defer ts.Close()
+
+	var persisted int32
+
+	src := NewRotatingTokenSource("client", "secret", Token{RefreshToken: "xoxe-1"}, func(ctx context.Context, token Token) error {
+		atomic.AddInt32(&persisted, 1)
+		return ctx.Err()
+	}, RefreshAPIOptions(ServerAddress(ts.URL)), RefreshTimeout(10*time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the caller gives up waiting, but the refresh goes on
+	if _, err := src.Token(ctx); err != context.DeadlineExceeded {
+		t.Errorf("waiting must end with the context, but %v", err)
+	}
+
+	close(release)
+
+	token, err := src.Token(context.Background())
+	if err != nil || token != "xoxe.xoxb-2" {
+		t.Fatalf("refreshed token must be returned: %v, %s", err, token)
+	}
+
+	if persisted != 1 || src.Current().RefreshToken != "xoxe-2" {
+		t.Errorf("refresh must be persisted once, but %d", persisted)
+	}
+}
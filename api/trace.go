@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/scryner/util.slack/trace"
+)
+
+// Tracing starts a span for every outbound call as a child of the span in the
+// context given to the XXXContext methods (e.g., by server.RequestContext)
+func Tracing(tracer trace.Tracer) Option {
+	return func(api *API) error {
+		api.interceptors = append(api.interceptors, func(ctx context.Context, call *Call, next Invoker) (*Result, error) {
+			ctx, span := tracer.Start(ctx, "slack.api "+call.Method, trace.String("slack.method", call.Method))
+			defer span.End()
+
+			result, err := next(ctx, call)
+			if err != nil {
+				span.RecordError(err)
+				return result, err
+			}
+
+			span.SetAttributes(
+				trace.Int("http.status_code", result.StatusCode),
+				trace.Bool("slack.ok", result.OK),
+			)
+
+			if !result.OK && result.Error != "" {
+				span.SetAttributes(trace.String("slack.error", result.Error))
+				span.RecordError(errors.New(result.Error))
+			}
+
+			return result, err
+		})
+
+		return nil
+	}
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type UserGroup struct {
+	ID          string   `json:"id"`
+	TeamID      string   `json:"team_id"`
+	IsUserGroup bool     `json:"is_usergroup"`
+	IsExternal  bool     `json:"is_external"`
+	Name        string   `json:"name"`
+	Handle      string   `json:"handle"`
+	Description string   `json:"description"`
+	DateCreate  int64    `json:"date_create"`
+	DateUpdate  int64    `json:"date_update"`
+	DateDelete  int64    `json:"date_delete"`
+	CreatedBy   string   `json:"created_by"`
+	UpdatedBy   string   `json:"updated_by"`
+	DeletedBy   string   `json:"deleted_by"`
+	AutoType    string   `json:"auto_type"`
+	UserCount   int      `json:"user_count"`
+	Users       []string `json:"users,omitempty"`
+
+	Prefs struct {
+		Channels []string `json:"channels"`
+		Groups   []string `json:"groups"`
+	} `json:"prefs"`
+}
+
+// IsEnabled reports whether the group is not disabled
+func (group *UserGroup) IsEnabled() bool {
+	return group.DateDelete == 0
+}
+
+type ListUserGroupsParams struct {
+	IncludeCount    bool
+	IncludeDisabled bool
+	IncludeUsers    bool
+	TeamID          string
+}
+
+func (params *ListUserGroupsParams) values() url.Values {
+	vals := make(url.Values)
+	if params == nil {
+		return vals
+	}
+
+	if params.IncludeCount {
+		vals.Set("include_count", strconv.FormatBool(params.IncludeCount))
+	}
+
+	if params.IncludeDisabled {
+		vals.Set("include_disabled", strconv.FormatBool(params.IncludeDisabled))
+	}
+
+	if params.IncludeUsers {
+		vals.Set("include_users", strconv.FormatBool(params.IncludeUsers))
+	}
+
+	if params.TeamID != "" {
+		vals.Set("team_id", params.TeamID)
+	}
+
+	return vals
+}
+
+type userGroupsListResponse struct {
+	UserGroups []UserGroup `json:"usergroups"`
+	genericResponse
+}
+
+func (resp *userGroupsListResponse) items() int {
+	return len(resp.UserGroups)
+}
+
+func (api *API) ListUserGroups(params *ListUserGroupsParams, opts *PageOptions) ([]UserGroup, error) {
+	return api.ListUserGroupsContext(context.Background(), params, opts)
+}
+
+func (api *API) ListUserGroupsContext(ctx context.Context, params *ListUserGroupsParams, opts *PageOptions) ([]UserGroup, error) {
+	var groups []UserGroup
+
+	err := api.EachUserGroupContext(ctx, params, opts, func(group *UserGroup) error {
+		groups = append(groups, *group)
+		return nil
+	})
+
+	return groups, err
+}
+
+func (api *API) EachUserGroup(params *ListUserGroupsParams, opts *PageOptions, fn func(*UserGroup) error) error {
+	return api.EachUserGroupContext(context.Background(), params, opts, fn)
+}
+
+func (api *API) EachUserGroupContext(ctx context.Context, params *ListUserGroupsParams, opts *PageOptions, fn func(*UserGroup) error) error {
+	// usergroups.list returns everything at once and takes no page size;
+	// paging just applies the limit
+	p := api.newPagerWithSizeParam("usergroups.list", params.values(), opts, "")
+
+	var page *userGroupsListResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(userGroupsListResponse)
+		return page
+	}, func(i int) error {
+		return fn(&page.UserGroups[i])
+	})
+}
+
+// UserGroupParams are properties of a user group; empty ones are left as is
+// on update
+type UserGroupParams struct {
+	Name        string
+	Handle      string
+	Description string
+	Channels    []string // default channels of members
+}
+
+type userGroupRequest struct {
+	UserGroupId string `json:"usergroup,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Handle      string `json:"handle,omitempty"`
+	Description string `json:"description,omitempty"`
+	Channels    string `json:"channels,omitempty"`
+}
+
+func (params *UserGroupParams) request(groupId string) userGroupRequest {
+	return userGroupRequest{
+		UserGroupId: groupId,
+		Name:        params.Name,
+		Handle:      params.Handle,
+		Description: params.Description,
+		Channels:    strings.Join(params.Channels, ","),
+	}
+}
+
+type userGroupResponse struct {
+	UserGroup UserGroup `json:"usergroup"`
+	genericResponse
+}
+
+func (api *API) CreateUserGroup(params *UserGroupParams) (*UserGroup, error) {
+	return api.CreateUserGroupContext(context.Background(), params)
+}
+
+func (api *API) CreateUserGroupContext(ctx context.Context, params *UserGroupParams) (*UserGroup, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty user group params")
+	}
+
+	var resp userGroupResponse
+
+	err := api.postJSON(ctx, "usergroups.create", params.request(""), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserGroup, nil
+}
+
+func (api *API) UpdateUserGroup(groupId string, params *UserGroupParams) (*UserGroup, error) {
+	return api.UpdateUserGroupContext(context.Background(), groupId, params)
+}
+
+func (api *API) UpdateUserGroupContext(ctx context.Context, groupId string, params *UserGroupParams) (*UserGroup, error) {
+	if params == nil {
+		return nil, fmt.Errorf("empty user group params")
+	}
+
+	var resp userGroupResponse
+
+	err := api.postJSON(ctx, "usergroups.update", params.request(groupId), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserGroup, nil
+}
+
+type userGroupIdRequest struct {
+	UserGroupId string `json:"usergroup"`
+}
+
+func (api *API) EnableUserGroup(groupId string) (*UserGroup, error) {
+	return api.EnableUserGroupContext(context.Background(), groupId)
+}
+
+func (api *API) EnableUserGroupContext(ctx context.Context, groupId string) (*UserGroup, error) {
+	var resp userGroupResponse
+
+	err := api.postJSON(ctx, "usergroups.enable", userGroupIdRequest{
+		UserGroupId: groupId,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserGroup, nil
+}
+
+func (api *API) DisableUserGroup(groupId string) (*UserGroup, error) {
+	return api.DisableUserGroupContext(context.Background(), groupId)
+}
+
+func (api *API) DisableUserGroupContext(ctx context.Context, groupId string) (*UserGroup, error) {
+	var resp userGroupResponse
+
+	err := api.postJSON(ctx, "usergroups.disable", userGroupIdRequest{
+		UserGroupId: groupId,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserGroup, nil
+}
+
+type userGroupUsersResponse struct {
+	Users []string `json:"users"`
+	genericResponse
+}
+
+// ListUserGroupMembers returns IDs of users in the group
+func (api *API) ListUserGroupMembers(groupId string, includeDisabled bool) ([]string, error) {
+	return api.ListUserGroupMembersContext(context.Background(), groupId, includeDisabled)
+}
+
+func (api *API) ListUserGroupMembersContext(ctx context.Context, groupId string, includeDisabled bool) ([]string, error) {
+	params := make(url.Values)
+	params.Set("usergroup", groupId)
+
+	if includeDisabled {
+		params.Set("include_disabled", strconv.FormatBool(includeDisabled))
+	}
+
+	var resp userGroupUsersResponse
+
+	err := api.get(ctx, "usergroups.users.list", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Users, nil
+}
+
+type updateUserGroupMembersRequest struct {
+	UserGroupId string `json:"usergroup"`
+	Users       string `json:"users"`
+}
+
+// SetUserGroupMembers replaces all members of the group with userIds, e.g.
+// to rotate an on-call handle
+func (api *API) SetUserGroupMembers(groupId string, userIds ...string) (*UserGroup, error) {
+	return api.SetUserGroupMembersContext(context.Background(), groupId, userIds...)
+}
+
+func (api *API) SetUserGroupMembersContext(ctx context.Context, groupId string, userIds ...string) (*UserGroup, error) {
+	var resp userGroupResponse
+
+	err := api.postJSON(ctx, "usergroups.users.update", updateUserGroupMembersRequest{
+		UserGroupId: groupId,
+		Users:       strings.Join(userIds, ","),
+	}, &resp)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.UserGroup, nil
+}
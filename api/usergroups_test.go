@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListUserGroups(t *testing.T) {
+	calls := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		q := r.URL.Query()
+		if q.Get("include_users") != "true" || q.Get("team_id") != "T1" {
+			t.Errorf("unexpected query: %v", q)
+		}
+
+		// usergroups.list takes no page size
+		if q.Get("limit") != "" || q.Get("count") != "" {
+			t.Errorf("page size must not be sent: %v", q)
+		}
+
+		w.Write([]byte(`{"ok":true,"usergroups":[{"id":"S1","handle":"oncall","users":["U1"]},{"id":"S2","handle":"dev","date_delete":1}]}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	groups, err := slack.ListUserGroups(&ListUserGroupsParams{IncludeUsers: true, TeamID: "T1"}, nil)
+	if err != nil {
+		t.Fatal("failed to list user groups:", err)
+	}
+
+	if len(groups) != 2 || groups[0].Handle != "oncall" || !groups[0].IsEnabled() || groups[1].IsEnabled() {
+		t.Errorf("unexpected user groups: %+v", groups)
+	}
+
+	// the limit applies to the items of the only page
+	groups, err = slack.ListUserGroups(&ListUserGroupsParams{IncludeUsers: true, TeamID: "T1"}, &PageOptions{Max: 1})
+	if err != nil {
+		t.Fatal("failed to list user groups:", err)
+	}
+
+	if len(groups) != 1 || groups[0].ID != "S1" || calls != 2 {
+		t.Errorf("unexpected user groups: %+v (%d calls)", groups, calls)
+	}
+}
+
+func TestManageUserGroups(t *testing.T) {
+	var method string
+	var req map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.URL.Path
+
+		if r.URL.Path == "/api/usergroups.users.list" {
+			q := r.URL.Query()
+			if q.Get("usergroup") != "S1" || q.Get("include_disabled") != "true" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"users":["U1","U2"]}`))
+			return
+		}
+
+		req = nil
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.Write([]byte(`{"ok":true,"usergroup":{"id":"S1","name":"On-call","handle":"oncall"}}`))
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	for _, tc := range []struct {
+		method   string
+		expected map[string]interface{}
+		call     func() (*UserGroup, error)
+	}{
+		{"/api/usergroups.create", map[string]interface{}{"name": "On-call", "handle": "oncall", "channels": "C1,C2"}, func() (*UserGroup, error) {
+			return slack.CreateUserGroup(&UserGroupParams{Name: "On-call", Handle: "oncall", Channels: []string{"C1", "C2"}})
+		}},
+		{"/api/usergroups.update", map[string]interface{}{"usergroup": "S1", "description": "pager"}, func() (*UserGroup, error) {
+			return slack.UpdateUserGroup("S1", &UserGroupParams{Description: "pager"})
+		}},
+		{"/api/usergroups.enable", map[string]interface{}{"usergroup": "S1"}, func() (*UserGroup, error) {
+			return slack.EnableUserGroup("S1")
+		}},
+		{"/api/usergroups.disable", map[string]interface{}{"usergroup": "S1"}, func() (*UserGroup, error) {
+			return slack.DisableUserGroup("S1")
+		}},
+		{"/api/usergroups.users.update", map[string]interface{}{"usergroup": "S1", "users": "U1,U2"}, func() (*UserGroup, error) {
+			return slack.SetUserGroupMembers("S1", "U1", "U2")
+		}},
+	} {
+		group, err := tc.call()
+		if err != nil {
+			t.Errorf("%s: failed to call: %v", tc.method, err)
+			continue
+		}
+
+		if method != tc.method {
+			t.Errorf("%s: unexpected method: %s", tc.method, method)
+		}
+
+		if len(req) != len(tc.expected) {
+			t.Errorf("%s: unexpected request: %v", tc.method, req)
+		}
+
+		for k, v := range tc.expected {
+			if req[k] != v {
+				t.Errorf("%s: unexpected %s: %v", tc.method, k, req[k])
+			}
+		}
+
+		if group.ID != "S1" || group.Handle != "oncall" {
+			t.Errorf("%s: unexpected user group: %+v", tc.method, group)
+		}
+	}
+
+	members, err := slack.ListUserGroupMembers("S1", true)
+	if err != nil || len(members) != 2 || members[1] != "U2" {
+		t.Errorf("unexpected members: %v, %v", err, members)
+	}
+
+	// nil params are rejected instead of panicking
+	if _, err = slack.CreateUserGroup(nil); err == nil {
+		t.Error("nil params must be rejected by CreateUserGroup")
+	}
+
+	if _, err = slack.UpdateUserGroup("S1", nil); err == nil {
+		t.Error("nil params must be rejected by UpdateUserGroup")
+	}
+}
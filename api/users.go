@@ -1,33 +1,114 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 )
 
 type User struct {
-	ID       string `json:"id"`
-	TeamID   string `json:"team_id"`
-	Name     string `json:"name"`
-	Color    string `json:"color"`
-	TzOffset int    `json:"tz_offset"`
+	ID                string `json:"id"`
+	TeamID            string `json:"team_id"`
+	Name              string `json:"name"`
+	RealName          string `json:"real_name"`
+	Deleted           bool   `json:"deleted"`
+	Color             string `json:"color"`
+	Tz                string `json:"tz"`
+	TzLabel           string `json:"tz_label"`
+	TzOffset          int    `json:"tz_offset"`
+	Locale            string `json:"locale"`
+	IsAdmin           bool   `json:"is_admin"`
+	IsOwner           bool   `json:"is_owner"`
+	IsPrimaryOwner    bool   `json:"is_primary_owner"`
+	IsRestricted      bool   `json:"is_restricted"`
+	IsUltraRestricted bool   `json:"is_ultra_restricted"`
+	IsBot             bool   `json:"is_bot"`
+	IsAppUser         bool   `json:"is_app_user"`
+	IsEmailConfirmed  bool   `json:"is_email_confirmed"`
+	Has2FA            bool   `json:"has_2fa"`
+	Updated           int64  `json:"updated"`
 
-	Profile struct {
-		Email string `json:"email"`
-	} `json:"profile"`
-	DMChannel string `json:"-"`
+	Profile   UserProfile `json:"profile"`
+	DMChannel string      `json:"-"`
+}
+
+// DisplayName returns the name shown in Slack
+func (user *User) DisplayName() string {
+	switch {
+	case user.Profile.DisplayName != "":
+		return user.Profile.DisplayName
+	case user.Profile.RealName != "":
+		return user.Profile.RealName
+	case user.RealName != "":
+		return user.RealName
+	default:
+		return user.Name
+	}
+}
+
+type UserProfile struct {
+	Title                 string `json:"title"`
+	Phone                 string `json:"phone"`
+	Skype                 string `json:"skype"`
+	RealName              string `json:"real_name"`
+	RealNameNormalized    string `json:"real_name_normalized"`
+	DisplayName           string `json:"display_name"`
+	DisplayNameNormalized string `json:"display_name_normalized"`
+	FirstName             string `json:"first_name"`
+	LastName              string `json:"last_name"`
+	Email                 string `json:"email"`
+	Pronouns              string `json:"pronouns"`
+	StatusText            string `json:"status_text"`
+	StatusEmoji           string `json:"status_emoji"`
+	StatusExpiration      int64  `json:"status_expiration"`
+	AvatarHash            string `json:"avatar_hash"`
+	Image24               string `json:"image_24"`
+	Image32               string `json:"image_32"`
+	Image48               string `json:"image_48"`
+	Image72               string `json:"image_72"`
+	Image192              string `json:"image_192"`
+	Image512              string `json:"image_512"`
+	Image1024             string `json:"image_1024"`
+	ImageOriginal         string `json:"image_original"`
+	Team                  string `json:"team"`
+	BotID                 string `json:"bot_id"`
+	ApiAppID              string `json:"api_app_id"`
+
+	// custom profile fields keyed by field id
+	Fields map[string]UserProfileField `json:"fields"`
+}
+
+type UserProfileField struct {
+	Value string `json:"value"`
+	Alt   string `json:"alt"`
+	Label string `json:"label,omitempty"`
+}
+
+// cacheUser stores user to both caches, keeping DM channel already known
+func (api *API) cacheUser(user *User) {
+	if iCached, ok, _ := api.idToUserCache.Get(user.ID); ok {
+		if cached, ok := iCached.(*User); ok && user.DMChannel == "" {
+			user.DMChannel = cached.DMChannel
+		}
+	}
+
+	api.idToUserCache.Set(user.ID, user)
+
+	if user.Profile.Email != "" {
+		api.emailToUserCache.Set(user.Profile.Email, user)
+	}
 }
 
 type userInfoResponse struct {
-	OK    bool   `json:"ok"`
-	User  User   `json:"user"`
-	Error string `json:"error"`
+	User User `json:"user"`
+	genericResponse
 }
 
 func (api *API) SearchUserByEmail(email string) (*User, error) {
+	return api.SearchUserByEmailContext(context.Background(), email)
+}
+
+func (api *API) SearchUserByEmailContext(ctx context.Context, email string) (*User, error) {
 	// try to get from cache
 	iUser, ok, err := api.emailToUserCache.Get(email)
 	if err != nil {
@@ -44,27 +125,11 @@ func (api *API) SearchUserByEmail(email string) (*User, error) {
 	params.Set("email", email)
 
 	// request
-	resp, err := api.doHTTPGet("api/users.lookupByEmail", params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to do search user: %v", err)
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to do search user: status code = %s", resp.Status)
-	}
-
-	// parse user info
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read search user body: %v", err)
-	}
-
 	var lookupResp userInfoResponse
-	err = json.Unmarshal(body, &lookupResp)
+
+	err = api.get(ctx, "users.lookupByEmail", params, &lookupResp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal result: %v", err)
+		return nil, err
 	}
 
 	user := lookupResp.User
@@ -73,12 +138,16 @@ func (api *API) SearchUserByEmail(email string) (*User, error) {
 	}
 
 	api.emailToUserCache.Set(email, &user)
-	api.idToUserCache.Set(user.ID, &user)
+	api.cacheUser(&user)
 
 	return &user, nil
 }
 
 func (api *API) GetUserInfo(id string) (*User, error) {
+	return api.GetUserInfoContext(context.Background(), id)
+}
+
+func (api *API) GetUserInfoContext(ctx context.Context, id string) (*User, error) {
 	// try to get from cache
 	iUser, ok, err := api.idToUserCache.Get(id)
 	if err != nil {
@@ -95,40 +164,125 @@ func (api *API) GetUserInfo(id string) (*User, error) {
 	params.Set("user", id)
 
 	// request
-	resp, err := api.doHTTPGet("api/users.info", params)
+	var userInfoResp userInfoResponse
+
+	err = api.get(ctx, "users.info", params, &userInfoResp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to do get user info: %v", err)
+		return nil, err
 	}
 
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to do get user info: status code = %s", resp.Status)
+	user := userInfoResp.User
+	if user.ID == "" {
+		return nil, fmt.Errorf("no matching user")
 	}
 
-	// parse user info
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read user info body: %v", err)
-	}
+	api.cacheUser(&user)
 
-	var userInfoResp userInfoResponse
-	err = json.Unmarshal(body, &userInfoResp)
+	return &user, nil
+}
+
+type usersListResponse struct {
+	Members []User `json:"members"`
+	genericResponse
+}
+
+func (resp *usersListResponse) items() int {
+	return len(resp.Members)
+}
+
+// ListUsers returns all users of the workspace; listed users are cached as
+// well
+func (api *API) ListUsers(opts *PageOptions) ([]User, error) {
+	return api.ListUsersContext(context.Background(), opts)
+}
+
+func (api *API) ListUsersContext(ctx context.Context, opts *PageOptions) ([]User, error) {
+	var users []User
+
+	err := api.EachUserContext(ctx, opts, func(user *User) error {
+		users = append(users, *user)
+		return nil
+	})
+
+	return users, err
+}
+
+func (api *API) EachUser(opts *PageOptions, fn func(*User) error) error {
+	return api.EachUserContext(context.Background(), opts, fn)
+}
+
+func (api *API) EachUserContext(ctx context.Context, opts *PageOptions, fn func(*User) error) error {
+	p := api.newPager("users.list", nil, opts)
+
+	var page *usersListResponse
+
+	return p.each(ctx, func() listResponse {
+		page = new(usersListResponse)
+		return page
+	}, func(i int) error {
+		user := page.Members[i]
+		api.cacheUser(&user)
+
+		return fn(&user)
+	})
+}
+
+type userProfileResponse struct {
+	Profile UserProfile `json:"profile"`
+	genericResponse
+}
+
+func (api *API) GetUserProfile(userId string) (*UserProfile, error) {
+	return api.GetUserProfileContext(context.Background(), userId)
+}
+
+func (api *API) GetUserProfileContext(ctx context.Context, userId string) (*UserProfile, error) {
+	params := make(url.Values)
+	params.Set("user", userId)
+	params.Set("include_labels", "true")
+
+	var resp userProfileResponse
+
+	err := api.get(ctx, "users.profile.get", params, &resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal result: %v", err)
+		return nil, err
 	}
 
-	if !userInfoResp.OK {
-		return nil, fmt.Errorf("api/users.info failed: %s", userInfoResp.Error)
-	}
+	return &resp.Profile, nil
+}
 
-	user := userInfoResp.User
-	if user.ID == "" || user.Profile.Email == "" {
-		return nil, fmt.Errorf("no matching user")
+type setUserProfileRequest struct {
+	User    string                 `json:"user,omitempty"`
+	Profile map[string]interface{} `json:"profile"`
+}
+
+// SetUserProfile updates only the given fields of the profile, e.g.
+// {"status_text": "in a meeting", "status_emoji": ":calendar:"}; custom
+// fields go under "fields". Updating other users requires an admin token.
+func (api *API) SetUserProfile(userId string, fields map[string]interface{}) (*UserProfile, error) {
+	return api.SetUserProfileContext(context.Background(), userId, fields)
+}
+
+func (api *API) SetUserProfileContext(ctx context.Context, userId string, fields map[string]interface{}) (*UserProfile, error) {
+	var resp userProfileResponse
+
+	err := api.postJSON(ctx, "users.profile.set", setUserProfileRequest{
+		User:    userId,
+		Profile: fields,
+	}, &resp)
+
+	if err != nil {
+		return nil, err
 	}
 
-	api.idToUserCache.Set(id, &user)
-	api.emailToUserCache.Set(user.Profile.Email, &user)
+	// refresh cached user
+	if iCached, ok, _ := api.idToUserCache.Get(userId); ok {
+		if cached, ok := iCached.(*User); ok {
+			user := *cached
+			user.Profile = resp.Profile
+			api.cacheUser(&user)
+		}
+	}
 
-	return &user, nil
+	return &resp.Profile, nil
 }
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUserInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("user") {
+		case "U1":
+			w.Write([]byte(`{"ok":true,"user":{"id":"U1","team_id":"T1","name":"alice","real_name":"Alice Kim","tz":"Asia/Seoul","tz_offset":32400,"is_admin":true,"has_2fa":true,"updated":1630000000,"profile":{"title":"engineer","display_name":"alice","email":"alice@example.com","status_emoji":":coffee:","status_expiration":1630003600,"image_72":"https://example.com/a.png","fields":{"Xf1":{"value":"platform","alt":""}}}}}`))
+
+		case "B1":
+			// bot users have no email
+			w.Write([]byte(`{"ok":true,"user":{"id":"B1","name":"bot","is_bot":true,"profile":{"bot_id":"B1","real_name":"Bot"}}}`))
+
+		default:
+			t.Errorf("unexpected query: %v", r.URL.Query())
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	user, err := slack.GetUserInfo("U1")
+	if err != nil {
+		t.Fatal("failed to get user info:", err)
+	}
+
+	if user.TeamID != "T1" || user.Tz != "Asia/Seoul" || user.TzOffset != 32400 || !user.IsAdmin || !user.Has2FA || user.Updated != 1630000000 {
+		t.Errorf("unexpected user: %+v", user)
+	}
+
+	profile := user.Profile
+	if profile.Title != "engineer" || profile.Email != "alice@example.com" || profile.StatusEmoji != ":coffee:" || profile.StatusExpiration != 1630003600 || profile.Image72 == "" {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	if profile.Fields["Xf1"].Value != "platform" || user.DisplayName() != "alice" {
+		t.Errorf("unexpected fields: %+v", profile.Fields)
+	}
+
+	if cached, err := slack.SearchUserByEmail("alice@example.com"); err != nil || cached.ID != "U1" {
+		t.Errorf("user must be cached by email: %v, %v", cached, err)
+	}
+
+	bot, err := slack.GetUserInfo("B1")
+	if err != nil {
+		t.Fatal("failed to get user info without email:", err)
+	}
+
+	if !bot.IsBot || bot.Profile.BotID != "B1" || bot.DisplayName() != "Bot" {
+		t.Errorf("unexpected bot user: %+v", bot)
+	}
+}
+
+func TestUserProfile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users.info":
+			w.Write([]byte(`{"ok":true,"user":{"id":"U1","name":"alice","profile":{"email":"alice@example.com"}}}`))
+
+		case "/api/users.profile.get":
+			q := r.URL.Query()
+			if q.Get("user") != "U1" || q.Get("include_labels") != "true" {
+				t.Errorf("unexpected query: %v", q)
+			}
+
+			w.Write([]byte(`{"ok":true,"profile":{"email":"alice@example.com","status_text":"lunch","fields":{"Xf1":{"value":"platform","alt":"","label":"Team"}}}}`))
+
+		case "/api/users.profile.set":
+			var req setUserProfileRequest
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if req.User != "U1" || req.Profile["status_text"] != "in a meeting" || req.Profile["status_emoji"] != ":calendar:" {
+				t.Errorf("unexpected request: %+v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"profile":{"email":"alice@example.com","status_text":"in a meeting","status_emoji":":calendar:"}}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	profile, err := slack.GetUserProfile("U1")
+	if err != nil {
+		t.Fatal("failed to get user profile:", err)
+	}
+
+	if profile.StatusText != "lunch" || profile.Fields["Xf1"].Label != "Team" {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	// cache the user to see it refreshed by SetUserProfile
+	if _, err = slack.GetUserInfo("U1"); err != nil {
+		t.Fatal("failed to get user info:", err)
+	}
+
+	profile, err = slack.SetUserProfile("U1", map[string]interface{}{
+		"status_text":  "in a meeting",
+		"status_emoji": ":calendar:",
+	})
+	if err != nil {
+		t.Fatal("failed to set user profile:", err)
+	}
+
+	if profile.StatusText != "in a meeting" || profile.StatusEmoji != ":calendar:" {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+
+	user, err := slack.GetUserInfo("U1")
+	if err != nil {
+		t.Fatal("failed to get user info:", err)
+	}
+
+	if user.Name != "alice" || user.Profile.StatusText != "in a meeting" {
+		t.Errorf("cached user must be refreshed: %+v", user)
+	}
+}
@@ -1,12 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"reflect"
-	"strings"
 
 	"github.com/scryner/util.slack/block"
 	"github.com/scryner/util.slack/secret"
@@ -28,6 +25,24 @@ type View struct {
 	SubmitDisabled  *bool            `json:"submit_disabled,omitempty"`
 }
 
+type viewAlias View
+
+// MarshalJSON omits empty title, which home views do not need
+func (v View) MarshalJSON() ([]byte, error) {
+	var title *block.PlainText
+	if v.Title.Text != "" {
+		title = &v.Title
+	}
+
+	return json.Marshal(struct {
+		viewAlias
+		Title *block.PlainText `json:"title,omitempty"`
+	}{
+		viewAlias: viewAlias(v),
+		Title:     title,
+	})
+}
+
 func (data PrivateMetadata) MarshalJSON() ([]byte, error) {
 	encoded, err := secret.Encode(data)
 	if err != nil {
@@ -38,72 +53,67 @@ func (data PrivateMetadata) MarshalJSON() ([]byte, error) {
 	return json.Marshal(encoded)
 }
 
-type responseMetadata struct {
-	Messages []string `json:"messages"`
+// ViewInfo is a view as returned by Slack; Hash is to be given to UpdateView
+// for optimistic concurrency control
+type ViewInfo struct {
+	ID                 string          `json:"id"`
+	TeamID             string          `json:"team_id"`
+	Type               string          `json:"type"`
+	CallbackID         string          `json:"callback_id"`
+	ExternalID         string          `json:"external_id"`
+	Hash               string          `json:"hash"`
+	RootViewID         string          `json:"root_view_id"`
+	PreviousViewID     string          `json:"previous_view_id"`
+	AppID              string          `json:"app_id"`
+	BotID              string          `json:"bot_id"`
+	RawPrivateMetadata string          `json:"private_metadata"`
+	Blocks             json.RawMessage `json:"blocks"`
+	State              json.RawMessage `json:"state"`
+}
+
+func (v *ViewInfo) GetPrivateMetadata() []byte {
+	decoded, _ := secret.Decode(v.RawPrivateMetadata)
+	return decoded
 }
 
-type genericResponse struct {
-	OK               bool             `json:"ok"`
-	Error            string           `json:"error"`
-	ResponseMetadata responseMetadata `json:"response_metadata"`
+type viewResponse struct {
+	View ViewInfo `json:"view"`
+	genericResponse
 }
 
-func (api *API) PublishHomeView(user *User, blocks []block.Block) error {
-	return api.PublishView(user, &View{
-		Type: "home",
-		Title: block.PlainText{
-			Text:  "Hello, world!",
-			Emoji: false,
-		},
+// PublishHomeView publishes blocks as the home tab of the user
+func (api *API) PublishHomeView(userId string, blocks []block.Block) (*ViewInfo, error) {
+	return api.PublishHomeViewContext(context.Background(), userId, blocks)
+}
+
+func (api *API) PublishHomeViewContext(ctx context.Context, userId string, blocks []block.Block) (*ViewInfo, error) {
+	return api.PublishViewContext(ctx, userId, "", &View{
+		Type:   "home",
 		Blocks: blocks,
 	})
 }
 
 type publishViewRequest struct {
 	UserId string `json:"user_id"`
+	Hash   string `json:"hash,omitempty"`
 	View   *View  `json:"view"`
 }
 
-func (api *API) PublishView(user *User, view *View) error {
+// PublishView publishes view (usually of "home" type) for the user; when hash
+// is given, Slack rejects it with ErrHashConflict if the view was changed in
+// the meantime
+func (api *API) PublishView(userId, hash string, view *View) (*ViewInfo, error) {
+	return api.PublishViewContext(context.Background(), userId, hash, view)
+}
+
+func (api *API) PublishViewContext(ctx context.Context, userId, hash string, view *View) (*ViewInfo, error) {
 	req := publishViewRequest{
-		UserId: user.ID,
+		UserId: userId,
+		Hash:   hash,
 		View:   view,
 	}
 
-	// do request
-	resp, err := api.doHTTPPostJSON("api/views.publish", nil, req)
-
-	if err != nil {
-		return fmt.Errorf("failed to send request to publish view: %v", err)
-	}
-
-	defer resp.Body.Close()
-
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to publish view: status = %s", resp.Status)
-	}
-
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to response body: %v", err)
-	}
-
-	// unmarshal generic response
-	var gresp genericResponse
-
-	err = json.Unmarshal(b, &gresp)
-	if err != nil {
-		// never reached
-		return fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
-
-	if !gresp.OK {
-		return fmt.Errorf("failed to publish view: %s", gresp.Error)
-	}
-
-	return nil
+	return api.requestView(ctx, "views.publish", req)
 }
 
 type openViewRequest struct {
@@ -111,122 +121,83 @@ type openViewRequest struct {
 	View      *View  `json:"view"`
 }
 
-type openViewResponse struct {
-	View map[string]interface{} `json:"view"`
-	genericResponse
+func (api *API) OpenView(triggerId string, view *View) (*ViewInfo, error) {
+	return api.OpenViewContext(context.Background(), triggerId, view)
 }
 
-func (api *API) OpenView(triggerId string, view *View) (viewId string, err error) {
+func (api *API) OpenViewContext(ctx context.Context, triggerId string, view *View) (*ViewInfo, error) {
 	req := openViewRequest{
 		TriggerId: triggerId,
 		View:      view,
 	}
 
-	// do request
-	resp, err := api.doHTTPPostJSON("api/views.open", nil, req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request to open view: %v", err)
-	}
-
-	defer resp.Body.Close()
-
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to open view: status = %s", resp.Status)
-	}
-
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to response body: %v", err)
-	}
-
-	// unmarshal generic response
-	var vResp openViewResponse
-
-	err = json.Unmarshal(b, &vResp)
-	if err != nil {
-		// never reached
-		return "", fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
-
-	if !vResp.OK {
-		var errMsg string
-
-		// try to get detailed error response
-		if len(vResp.ResponseMetadata.Messages) > 0 {
-			errMsg = fmt.Sprintf("%s (%s)", vResp.Error, strings.Join(vResp.ResponseMetadata.Messages, ", "))
-		} else {
-			errMsg = vResp.Error
-		}
-
-		return "", fmt.Errorf("failed to open view: %s", errMsg)
-	}
+	return api.requestView(ctx, "views.open", req)
+}
 
-	// extract id
-	iId, ok := vResp.View["id"]
-	if !ok {
-		return "", fmt.Errorf("empty view id")
-	}
+// PushView pushes view onto the stack of the modal opened by the trigger
+func (api *API) PushView(triggerId string, view *View) (*ViewInfo, error) {
+	return api.PushViewContext(context.Background(), triggerId, view)
+}
 
-	viewId, ok = iId.(string)
-	if !ok {
-		// never reached
-		return "", fmt.Errorf("invalid viewId type: %v", reflect.TypeOf(iId))
+func (api *API) PushViewContext(ctx context.Context, triggerId string, view *View) (*ViewInfo, error) {
+	req := openViewRequest{
+		TriggerId: triggerId,
+		View:      view,
 	}
 
-	return
+	return api.requestView(ctx, "views.push", req)
 }
 
 type updateViewRequest struct {
-	ViewId string `json:"view_id"`
-	Hash   string `json:"hash,omitempty"`
-	View   *View  `json:"view"`
+	ViewId     string `json:"view_id,omitempty"`
+	ExternalId string `json:"external_id,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+	View       *View  `json:"view"`
 }
 
-type updateViewResponse struct {
-	View map[string]interface{} `json:"view"`
-	genericResponse
+// UpdateView updates the view; when hash is given, Slack rejects the update
+// with ErrHashConflict if the view was changed in the meantime
+func (api *API) UpdateView(viewId, hash string, view *View) (*ViewInfo, error) {
+	return api.UpdateViewContext(context.Background(), viewId, hash, view)
 }
 
-func (api *API) UpdateView(viewId, hash string, view *View) error {
+func (api *API) UpdateViewContext(ctx context.Context, viewId, hash string, view *View) (*ViewInfo, error) {
 	req := updateViewRequest{
 		ViewId: viewId,
 		Hash:   hash,
 		View:   view,
 	}
 
-	// do request
-	resp, err := api.doHTTPPostJSON("api/views.update", nil, req)
-	if err != nil {
-		return fmt.Errorf("failed to send request to update view: %v", err)
-	}
+	return api.requestView(ctx, "views.update", req)
+}
 
-	defer resp.Body.Close()
+// UpdateViewByExternalId updates the view identified by the external_id
+// given when it was opened
+func (api *API) UpdateViewByExternalId(externalId, hash string, view *View) (*ViewInfo, error) {
+	return api.UpdateViewByExternalIdContext(context.Background(), externalId, hash, view)
+}
 
-	// check result
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to update view: status = %s", resp.Status)
+func (api *API) UpdateViewByExternalIdContext(ctx context.Context, externalId, hash string, view *View) (*ViewInfo, error) {
+	req := updateViewRequest{
+		ExternalId: externalId,
+		Hash:       hash,
+		View:       view,
 	}
 
-	// read response body
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to response body: %v", err)
-	}
+	return api.requestView(ctx, "views.update", req)
+}
 
-	// unmarshal generic response
-	var vResp updateViewResponse
+func (api *API) requestView(ctx context.Context, method string, req interface{}) (*ViewInfo, error) {
+	var vResp viewResponse
 
-	err = json.Unmarshal(b, &vResp)
+	err := api.postJSON(ctx, method, req, &vResp)
 	if err != nil {
-		// never reached
-		return fmt.Errorf("failed to unmarshal response body: %v", err)
+		return nil, err
 	}
 
-	if !vResp.OK {
-		return fmt.Errorf("failed to update view: %s", vResp.Error)
+	if vResp.View.ID == "" {
+		return nil, fmt.Errorf("empty view id")
 	}
 
-	return nil
+	return &vResp.View, nil
 }
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scryner/util.slack/block"
+)
+
+func TestPushAndUpdateView(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		view, _ := req["view"].(map[string]interface{})
+
+		switch r.URL.Path {
+		case "/api/views.push":
+			if req["trigger_id"] != "trigger" || view["type"] != "modal" || view["callback_id"] != "step2" {
+				t.Errorf("unexpected push request: %v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"view":{"id":"V2","type":"modal","callback_id":"step2","hash":"h2","root_view_id":"V1","previous_view_id":"V1"}}`))
+
+		case "/api/views.update":
+			if req["external_id"] != "ext-1" || req["hash"] != "h2" || view["type"] != "modal" {
+				t.Errorf("unexpected update request: %v", req)
+			}
+
+			if _, ok := req["view_id"]; ok {
+				t.Errorf("view_id must be omitted: %v", req)
+			}
+
+			w.Write([]byte(`{"ok":true,"view":{"id":"V2","type":"modal","external_id":"ext-1","hash":"h3","root_view_id":"V1","previous_view_id":"V1"}}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	slack, err := New("xoxb-test", ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	pushed, err := slack.PushView("trigger", &View{
+		Type:       "modal",
+		Title:      block.PlainText{Text: "Step 2"},
+		CallbackId: "step2",
+	})
+	if err != nil {
+		t.Fatal("failed to push view:", err)
+	}
+
+	if pushed.ID != "V2" || pushed.Hash != "h2" || pushed.RootViewID != "V1" || pushed.PreviousViewID != "V1" || pushed.CallbackID != "step2" {
+		t.Errorf("unexpected pushed view: %+v", pushed)
+	}
+
+	updated, err := slack.UpdateViewByExternalId("ext-1", pushed.Hash, &View{
+		Type:  "modal",
+		Title: block.PlainText{Text: "Step 2"},
+	})
+	if err != nil {
+		t.Fatal("failed to update view:", err)
+	}
+
+	if updated.ID != "V2" || updated.Hash != "h3" || updated.ExternalID != "ext-1" || updated.RootViewID != "V1" || updated.PreviousViewID != "V1" {
+		t.Errorf("unexpected updated view: %+v", updated)
+	}
+}
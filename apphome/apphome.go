@@ -0,0 +1,231 @@
+package apphome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scryner/util.slack/api"
+	"github.com/scryner/util.slack/server"
+)
+
+const (
+	DefaultDebounce       = 2 * time.Second
+	DefaultPublishTimeout = 10 * time.Second
+	DefaultMaxUsers       = 10000
+)
+
+// RenderFunc renders the home tab of the user; Type of the returned view is
+// set to "home" if it is empty
+type RenderFunc func(ctx context.Context, userId string) (*api.View, error)
+
+// Home keeps home tabs of users up to date: it renders and publishes the tab
+// whenever a user opens it, and re-publishes it (debounced) when Invalidate
+// is called.
+type Home struct {
+	slack  *api.API
+	render RenderFunc
+
+	debounce       time.Duration
+	publishTimeout time.Duration
+	onError        func(userId string, err error)
+	maxUsers       int
+
+	hashes map[string]string // last published hash by user
+	timers map[string]*time.Timer
+	lock   *sync.Mutex
+}
+
+type Option func(*Home) error
+
+// Debounce sets how long Invalidate waits for more changes before publishing
+func Debounce(d time.Duration) Option {
+	return func(home *Home) error {
+		home.debounce = d
+		return nil
+	}
+}
+
+// PublishTimeout bounds publishing triggered by events and Invalidate
+func PublishTimeout(timeout time.Duration) Option {
+	return func(home *Home) error {
+		home.publishTimeout = timeout
+		return nil
+	}
+}
+
+// MaxUsers bounds the number of users whose hashes are kept; when exceeded,
+// the hash of an arbitrary user is forgotten and the next publishing for that
+// user is done without hash
+func MaxUsers(n int) Option {
+	return func(home *Home) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max users: %d", n)
+		}
+
+		home.maxUsers = n
+		return nil
+	}
+}
+
+// ErrorHandler is called when publishing triggered by Invalidate fails
+func ErrorHandler(handler func(userId string, err error)) Option {
+	return func(home *Home) error {
+		home.onError = handler
+		return nil
+	}
+}
+
+func New(slack *api.API, render RenderFunc, opts ...Option) (*Home, error) {
+	home := &Home{
+		slack:          slack,
+		render:         render,
+		debounce:       DefaultDebounce,
+		publishTimeout: DefaultPublishTimeout,
+		maxUsers:       DefaultMaxUsers,
+		hashes:         make(map[string]string),
+		timers:         make(map[string]*time.Timer),
+		lock:           new(sync.Mutex),
+	}
+
+	var err error
+	for _, opt := range opts {
+		err = opt(home)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if render == nil {
+		return nil, errors.New("empty render function")
+	}
+
+	return home, nil
+}
+
+// Publish renders and publishes the home tab of the user right now. The hash
+// of the last publishing is used to detect concurrent updates: if someone
+// else published in the meantime, api.ErrHashConflict is returned and the
+// other view is kept. Slack does not tell the current hash on conflict, so it
+// is learned again when the user opens the tab.
+func (home *Home) Publish(ctx context.Context, userId string) error {
+	home.lock.Lock()
+	hash := home.hashes[userId]
+	home.lock.Unlock()
+
+	view, err := home.render(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("failed to render home of '%s': %w", userId, err)
+	}
+
+	if view == nil {
+		return fmt.Errorf("failed to render home of '%s': empty view", userId)
+	}
+
+	if view.Type == "" {
+		view.Type = "home"
+	}
+
+	published, err := home.slack.PublishViewContext(ctx, userId, hash, view)
+	if err != nil {
+		return err
+	}
+
+	home.setHash(userId, published.Hash)
+	return nil
+}
+
+func (home *Home) setHash(userId, hash string) {
+	home.lock.Lock()
+	defer home.lock.Unlock()
+
+	if _, ok := home.hashes[userId]; !ok && len(home.hashes) >= home.maxUsers {
+		for evicted := range home.hashes {
+			delete(home.hashes, evicted)
+			break
+		}
+	}
+
+	home.hashes[userId] = hash
+}
+
+// Invalidate re-publishes home tabs of the users after the debounce delay;
+// calls within the delay are coalesced into one publishing per user
+func (home *Home) Invalidate(userIds ...string) {
+	home.lock.Lock()
+	defer home.lock.Unlock()
+
+	for _, userId := range userIds {
+		// a timer which already fired is publishing (or about to), so it
+		// cannot be postponed; another one is made for the later changes
+		if timer, ok := home.timers[userId]; ok && timer.Stop() {
+			timer.Reset(home.debounce)
+			continue
+		}
+
+		userId := userId
+
+		var timer *time.Timer
+		timer = time.AfterFunc(home.debounce, func() {
+			home.lock.Lock()
+			if home.timers[userId] == timer {
+				delete(home.timers, userId)
+			}
+			home.lock.Unlock()
+
+			ctx, cancel := context.WithTimeout(context.Background(), home.publishTimeout)
+			defer cancel()
+
+			if err := home.Publish(ctx, userId); err != nil && home.onError != nil {
+				home.onError(userId, err)
+			}
+		})
+
+		home.timers[userId] = timer
+	}
+}
+
+// InvalidateAll re-publishes home tabs of all users who have been published
+// so far
+func (home *Home) InvalidateAll() {
+	home.lock.Lock()
+	userIds := make([]string, 0, len(home.hashes))
+	for userId := range home.hashes {
+		userIds = append(userIds, userId)
+	}
+	home.lock.Unlock()
+
+	home.Invalidate(userIds...)
+}
+
+// Close cancels pending publishing
+func (home *Home) Close() {
+	home.lock.Lock()
+	defer home.lock.Unlock()
+
+	for userId, timer := range home.timers {
+		timer.Stop()
+		delete(home.timers, userId)
+	}
+}
+
+// HandleAppHomeOpened publishes the home tab when a user opens it; register
+// it by server.OnAppHomeOpened
+func (home *Home) HandleAppHomeOpened(sctx server.Context, _ *server.EventCallback, ev *server.AppHomeOpened) error {
+	if ev.Tab != "home" {
+		return nil
+	}
+
+	// the event tells the hash of the current view
+	if hash := ev.Hash(); hash != "" {
+		home.setHash(ev.User, hash)
+	}
+
+	// publishing joins the trace of the event
+	ctx, cancel := context.WithTimeout(server.RequestContext(sctx), home.publishTimeout)
+	defer cancel()
+
+	return home.Publish(ctx, ev.User)
+}
@@ -0,0 +1,137 @@
+package apphome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scryner/util.slack/api"
+	"github.com/scryner/util.slack/block"
+)
+
+type publishRecorder struct {
+	hashes []string
+	lock   sync.Mutex
+}
+
+func (rec *publishRecorder) count() int {
+	rec.lock.Lock()
+	defer rec.lock.Unlock()
+
+	return len(rec.hashes)
+}
+
+func newTestHome(t *testing.T, conflictHash string, opts ...Option) (*Home, *publishRecorder, func()) {
+	rec := new(publishRecorder)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			UserId string `json:"user_id"`
+			Hash   string `json:"hash"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		rec.lock.Lock()
+		rec.hashes = append(rec.hashes, req.Hash)
+		rec.lock.Unlock()
+
+		if conflictHash != "" && req.Hash == conflictHash {
+			w.Write([]byte(`{"ok":false,"error":"hash_conflict"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"view":{"id":"V1","hash":"h2"}}`))
+	}))
+
+	slack, err := api.New("xoxb-test", api.ServerAddress(ts.URL))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	home, err := New(slack, func(ctx context.Context, userId string) (*api.View, error) {
+		return &api.View{
+			Blocks: []block.Block{block.Section{Text: block.PlainText{Text: "hello " + userId}}},
+		}, nil
+	}, opts...)
+	if err != nil {
+		t.Fatal("failed to make home:", err)
+	}
+
+	return home, rec, ts.Close
+}
+
+func TestInvalidate(t *testing.T) {
+	home, rec, closeServer := newTestHome(t, "", Debounce(50*time.Millisecond))
+	defer closeServer()
+	defer home.Close()
+
+	for i := 0; i < 3; i++ {
+		home.Invalidate("U1")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	if n := rec.count(); n != 1 {
+		t.Errorf("home must be published once, but %d", n)
+	}
+}
+
+func TestHashConflict(t *testing.T) {
+	home, rec, closeServer := newTestHome(t, "h1")
+	defer closeServer()
+
+	home.hashes["U1"] = "h1"
+
+	// the view published by someone else must not be overwritten
+	if err := home.Publish(context.Background(), "U1"); !errors.Is(err, api.ErrHashConflict) {
+		t.Fatal("hash conflict must be returned, but", err)
+	}
+
+	if len(rec.hashes) != 1 {
+		t.Errorf("home must not be published again: %v", rec.hashes)
+	}
+
+	// the user opened the tab, which tells the current hash
+	home.hashes["U1"] = "h3"
+
+	if err := home.Publish(context.Background(), "U1"); err != nil {
+		t.Fatal("failed to publish:", err)
+	}
+
+	if home.hashes["U1"] != "h2" {
+		t.Errorf("hash must be updated, but '%s'", home.hashes["U1"])
+	}
+}
+
+func TestEmptyView(t *testing.T) {
+	home, err := New(new(api.API), func(ctx context.Context, userId string) (*api.View, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal("failed to make home:", err)
+	}
+
+	if err = home.Publish(context.Background(), "U1"); err == nil {
+		t.Error("empty view must be rejected")
+	}
+}
+
+func TestMaxUsers(t *testing.T) {
+	home, _, closeServer := newTestHome(t, "", MaxUsers(2))
+	defer closeServer()
+
+	for _, userId := range []string{"U1", "U2", "U3"} {
+		if err := home.Publish(context.Background(), userId); err != nil {
+			t.Fatal("failed to publish:", err)
+		}
+	}
+
+	if len(home.hashes) != 2 || home.hashes["U3"] != "h2" {
+		t.Errorf("hashes must be bounded: %v", home.hashes)
+	}
+}
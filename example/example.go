@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/scryner/util.slack/api"
+	"github.com/scryner/util.slack/apphome"
 	"github.com/scryner/util.slack/block"
 	"github.com/scryner/util.slack/server"
 )
@@ -49,7 +51,7 @@ func main() {
 	}
 
 	// publish home view
-	err = slack.PublishHomeView(user, []block.Block{
+	_, err = slack.PublishHomeView(user.ID, []block.Block{
 		block.Section{
 			Text: block.PlainText{
 				Text: "My sweet home",
@@ -66,11 +68,28 @@ func main() {
 		slack: slack,
 	}
 
+	// render home tab whenever it is opened
+	home, err := apphome.New(slack, func(ctx context.Context, userId string) (*api.View, error) {
+		return &api.View{
+			Blocks: []block.Block{
+				block.Section{
+					Text: block.MarkdownText{
+						Text: fmt.Sprintf("Welcome home, <@%s>", userId),
+					},
+				},
+			},
+		}, nil
+	})
+
+	if err != nil {
+		log.Fatal("failed to make home:", err)
+	}
+
 	s, err := server.New(signingSecret, server.ListenPort(8080),
 		server.LogLevel(server.DEBUG),
 		server.Handlers(
 			server.SlashCommand("/slash", h),
-			server.EventSubscriptions("/event", h),
+			server.EventSubscriptions("/event", h, server.OnAppHomeOpened(home)),
 			server.Interactivity("/interactivity", h),
 			server.Http(http.MethodPost, "/echo", func(ctx echo.Context) error {
 				// read body
@@ -112,7 +131,7 @@ func (h handler) HandleCommand(ctx server.Context, req *server.SlashCommandReque
 	t := true
 
 	// open modal view
-	_, err := h.slack.OpenView(req.TriggerId, &api.View{
+	_, err := h.slack.OpenViewContext(server.RequestContext(ctx), req.TriggerId, &api.View{
 		Type: "modal",
 		Title: block.PlainText{
 			Text:  fmt.Sprintf("Handle '%s' :+1:", req.Text),
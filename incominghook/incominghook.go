@@ -16,8 +16,9 @@ const (
 )
 
 type Notifier struct {
-	webhookURL string
+	webhookURL     string
 	requestTimeout time.Duration
+	httpCli        *http.Client
 }
 
 type Option func(*Notifier) error
@@ -30,10 +31,21 @@ func RequestTimeout(timeout time.Duration) Option {
 	}
 }
 
+// HTTPClient makes the notifier send requests by client (e.g., for a proxy or
+// mTLS); http.DefaultClient is used by default
+func HTTPClient(client *http.Client) Option {
+	return func(notifier *Notifier) error {
+		notifier.httpCli = client
+
+		return nil
+	}
+}
+
 func NewNotifier(webhookURL string, opts ...Option) (*Notifier, error) {
 	n := &Notifier{
 		webhookURL:     webhookURL,
 		requestTimeout: DefaultRequestTimeout,
+		httpCli:        http.DefaultClient,
 	}
 
 	var err error
@@ -60,13 +72,13 @@ func (notifier *Notifier) Notify(message block.Message) error {
 		return fmt.Errorf("failed to make notify request: %v", err)
 	}
 
-	ctx,cancel := context.WithTimeout(context.Background(), notifier.requestTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), notifier.requestTimeout)
 	defer cancel()
 
 	req = req.WithContext(ctx)
 
 	// do request
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := notifier.httpCli.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to notify request: %v", err)
 	}
@@ -78,4 +90,4 @@ func (notifier *Notifier) Notify(message block.Message) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -1,6 +1,8 @@
 package incominghook
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -26,4 +28,21 @@ func TestNotifier(t *testing.T) {
 		t.Error("failed to notify:", err)
 		t.FailNow()
 	}
-}
\ No newline at end of file
+}
+
+func TestNotifierHTTPClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	// the self-signed certificate is trusted only by the client of the server
+	notifier, err := NewNotifier(ts.URL, HTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatal("failed to make notifier:", err)
+	}
+
+	if err = notifier.Notify(block.PlainText{Text: "hello"}); err != nil {
+		t.Error("failed to notify:", err)
+	}
+}
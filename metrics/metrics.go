@@ -0,0 +1,276 @@
+// Package metrics is a tiny metrics registry exposing counters and histograms
+// in the Prometheus text exposition format, without depending on the
+// Prometheus client.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are upper bounds (in seconds) of histograms made without
+// buckets
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type kind string
+
+const (
+	counterKind   kind = "counter"
+	histogramKind kind = "histogram"
+)
+
+// Registry holds metric families; it is safe for concurrent use
+type Registry struct {
+	families map[string]*family
+	lock     *sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+		lock:     new(sync.Mutex),
+	}
+}
+
+type family struct {
+	name       string
+	help       string
+	kind       kind
+	labelNames []string
+	buckets    []float64
+
+	series map[string]*series
+	lock   *sync.Mutex
+}
+
+type series struct {
+	labelValues []string
+	value       float64 // counter
+
+	counts []uint64 // histogram; not cumulative
+	count  uint64
+	sum    float64
+}
+
+// register returns the family of name, making it if not exists; registering
+// the same name with another kind or labels panics since it is a programming
+// error
+func (r *Registry) register(name, help string, k kind, buckets []float64, labelNames []string) *family {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || strings.Join(f.labelNames, ",") != strings.Join(labelNames, ",") {
+			panic(fmt.Sprintf("metrics: '%s' is already registered as another metric", name))
+		}
+
+		return f
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       k,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+		lock:       new(sync.Mutex),
+	}
+
+	r.families[name] = f
+	return f
+}
+
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: '%s' needs %d label values, but %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{
+			labelValues: append([]string(nil), labelValues...),
+		}
+
+		if f.kind == histogramKind {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// Counter is a monotonically increasing value partitioned by labels
+type Counter struct {
+	family *family
+}
+
+// Counter registers (or returns the registered) counter
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	return &Counter{family: r.register(name, help, counterKind, nil, labelNames)}
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.family.lock.Lock()
+	defer c.family.lock.Unlock()
+
+	c.family.get(labelValues).value += v
+}
+
+// Histogram counts observations in buckets partitioned by labels
+type Histogram struct {
+	family *family
+}
+
+// Histogram registers (or returns the registered) histogram; DefaultBuckets
+// are used if buckets is empty
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Histogram{family: r.register(name, help, histogramKind, sorted, labelNames)}
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.family.lock.Lock()
+	defer h.family.lock.Unlock()
+
+	s := h.family.get(labelValues)
+
+	for i, bound := range h.family.buckets {
+		if v <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+
+	s.count++
+	s.sum += v
+}
+
+// WriteText writes all metrics in the text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.lock.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.lock.Unlock()
+
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+
+	for _, f := range families {
+		f.write(bw)
+	}
+
+	return bw.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := f.series[key]
+
+		switch f.kind {
+		case counterKind:
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labels(s.labelValues, "", ""), formatFloat(s.value))
+
+		case histogramKind:
+			var cumulative uint64
+
+			for i, bound := range f.buckets {
+				cumulative += s.counts[i]
+				fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", formatFloat(bound)), cumulative)
+			}
+
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, "le", "+Inf"), s.count)
+			fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labels(s.labelValues, "", ""), formatFloat(s.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labels(s.labelValues, "", ""), s.count)
+		}
+	}
+}
+
+func (f *family) labels(values []string, extraName, extraValue string) string {
+	pairs := make([]string, 0, len(values)+1)
+
+	for i, name := range f.labelNames {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escapeLabel(values[i])))
+	}
+
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, escapeLabel(extraValue)))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// Handler serves the metrics in the text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
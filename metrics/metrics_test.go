@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.Counter("requests_total", "Requests.", "type")
+	requests.Inc("event")
+	requests.Add(2, `say "hi"`)
+
+	latency := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(5)
+
+	// registering again returns the same metric
+	r.Counter("requests_total", "Requests.", "type").Inc("event")
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal("failed to write:", err)
+	}
+
+	expected := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 5.55
+latency_seconds_count 3
+# HELP requests_total Requests.
+# TYPE requests_total counter
+requests_total{type="event"} 2
+requests_total{type="say \"hi\""} 2
+`
+
+	if got := buf.String(); got != expected {
+		t.Errorf("unexpected exposition:\n%s", got)
+	}
+}
+
+func TestRegisterConflict(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("x", "", "a")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("conflicting registration must panic")
+		}
+	}()
+
+	r.Histogram("x", "", nil, "a")
+}
+
+func TestLabelValues(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("x", "", "a", "b").Inc("1", "2")
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+
+	if !strings.Contains(buf.String(), `x{a="1",b="2"} 1`) {
+		t.Errorf("unexpected exposition:\n%s", buf.String())
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"sync"
+
 	"github.com/labstack/echo/v4"
 )
 
@@ -9,3 +11,50 @@ type Context interface {
 	Get(key string) interface{}
 	Set(key string, val interface{})
 }
+
+// snapshotKeys are keys of values set by the server which are carried over
+// to a snapshot
+var snapshotKeys = []string{"reqBody", traceContextKey, metricsKey}
+
+// snapshotContext is a Context detached from the echo context of a request,
+// since echo reuses the context for another request once the handler returns
+type snapshotContext struct {
+	logger echo.Logger
+	values map[string]interface{}
+	lock   *sync.Mutex
+}
+
+// snapshot copies the logger and the values of ctx set by the server, for
+// handlers going on after the response (e.g., events)
+func snapshot(ctx Context) Context {
+	values := make(map[string]interface{})
+	for _, key := range snapshotKeys {
+		if val := ctx.Get(key); val != nil {
+			values[key] = val
+		}
+	}
+
+	return &snapshotContext{
+		logger: ctx.Logger(),
+		values: values,
+		lock:   new(sync.Mutex),
+	}
+}
+
+func (ctx *snapshotContext) Logger() echo.Logger {
+	return ctx.logger
+}
+
+func (ctx *snapshotContext) Get(key string) interface{} {
+	ctx.lock.Lock()
+	defer ctx.lock.Unlock()
+
+	return ctx.values[key]
+}
+
+func (ctx *snapshotContext) Set(key string, val interface{}) {
+	ctx.lock.Lock()
+	defer ctx.lock.Unlock()
+
+	ctx.values[key] = val
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/trace"
 )
 
 type Authorizations []Authorization
@@ -54,6 +55,7 @@ func (ev Event) Type() (typ, subType string, err error) {
 }
 
 type EventCallback struct {
+	EnterpriseId   string         `json:"enterprise_id"`
 	TeamId         string         `json:"team_id"`
 	ApiAppId       string         `json:"api_app_id"`
 	Event          Event          `json:"event"`
@@ -72,6 +74,9 @@ func (cb *EventCallback) UnmarshalJSON(b []byte) error {
 
 	for k, v := range m {
 		switch k {
+		case "enterprise_id":
+			_cb.EnterpriseId = safeToString(v)
+
 		case "team_id":
 			_cb.TeamId = safeToString(v)
 
@@ -137,7 +142,112 @@ type EventHandler interface {
 	HandleEvent(ctx Context, cb *EventCallback) error
 }
 
-func EventSubscriptions(endpoint string, handler EventHandler) handler {
+type SharedLink struct {
+	Domain string `json:"domain"`
+	Url    string `json:"url"`
+}
+
+// LinkShared is the 'link_shared' event sent when a link of a registered
+// domain is posted
+type LinkShared struct {
+	Channel         string       `json:"channel"`
+	User            string       `json:"user"`
+	MessageTs       string       `json:"message_ts"`
+	ThreadTs        string       `json:"thread_ts"`
+	Links           []SharedLink `json:"links"`
+	UnfurlId        string       `json:"unfurl_id"`
+	Source          string       `json:"source"`
+	IsBotUserMember bool         `json:"is_bot_user_member"`
+	EventTs         string       `json:"event_ts"`
+}
+
+type LinkSharedHandler interface {
+	HandleLinkShared(ctx Context, cb *EventCallback, linkShared *LinkShared) error
+}
+
+// AppHomeOpened is the 'app_home_opened' event sent when a user opens a tab
+// of the App Home
+type AppHomeOpened struct {
+	User    string `json:"user"`
+	Channel string `json:"channel"`
+	Tab     string `json:"tab"` // home or messages
+	EventTs string `json:"event_ts"`
+	View    View   `json:"view"` // currently published home view, if any
+}
+
+// Hash returns the hash of the currently published home view
+func (ev *AppHomeOpened) Hash() string {
+	return safeToString(ev.View["hash"])
+}
+
+type AppHomeOpenedHandler interface {
+	HandleAppHomeOpened(ctx Context, cb *EventCallback, appHomeOpened *AppHomeOpened) error
+}
+
+type eventHandlers struct {
+	linkShared    LinkSharedHandler
+	appHomeOpened AppHomeOpenedHandler
+}
+
+type EventOption func(*eventHandlers)
+
+// OnLinkShared dispatches 'link_shared' events to handler instead of the
+// EventHandler of EventSubscriptions
+func OnLinkShared(handler LinkSharedHandler) EventOption {
+	return func(handlers *eventHandlers) {
+		handlers.linkShared = handler
+	}
+}
+
+// OnAppHomeOpened dispatches 'app_home_opened' events to handler instead of
+// the EventHandler of EventSubscriptions
+func OnAppHomeOpened(handler AppHomeOpenedHandler) EventOption {
+	return func(handlers *eventHandlers) {
+		handlers.appHomeOpened = handler
+	}
+}
+
+// dispatch finds the handler for the event and returns a function to run it
+func (handlers *eventHandlers) dispatch(handler EventHandler, cb *EventCallback) (func(ctx Context) error, error) {
+	typ, _, err := cb.Event.Type()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case typ == "link_shared" && handlers.linkShared != nil:
+		var linkShared LinkShared
+		if err := unmarshalFromMap(cb.Event, &linkShared); err != nil {
+			return nil, err
+		}
+
+		return func(ctx Context) error {
+			return handlers.linkShared.HandleLinkShared(ctx, cb, &linkShared)
+		}, nil
+
+	case typ == "app_home_opened" && handlers.appHomeOpened != nil:
+		var appHomeOpened AppHomeOpened
+		if err := unmarshalFromMap(cb.Event, &appHomeOpened); err != nil {
+			return nil, err
+		}
+
+		return func(ctx Context) error {
+			return handlers.appHomeOpened.HandleAppHomeOpened(ctx, cb, &appHomeOpened)
+		}, nil
+
+	default:
+		return func(ctx Context) error {
+			return handler.HandleEvent(ctx, cb)
+		}, nil
+	}
+}
+
+func EventSubscriptions(endpoint string, handler EventHandler, opts ...EventOption) handler {
+	handlers := new(eventHandlers)
+	for _, opt := range opts {
+		opt(handlers)
+	}
+
 	return func() (string, string, echo.HandlerFunc, bool) {
 		return http.MethodPost, endpoint, func(ctx echo.Context) error {
 			// get request body
@@ -190,10 +300,34 @@ func EventSubscriptions(endpoint string, handler EventHandler) handler {
 					})
 				}
 
-				// handle it
+				handle, err := handlers.dispatch(handler, &cb)
+				if err != nil {
+					ctx.Logger().Errorf("failed to dispatch event: %v", err)
+					return ctx.JSON(http.StatusBadRequest, slackError{
+						ResponseType: "ephemeral",
+						Text:         "I can't understand the event",
+					})
+				}
+
+				evTyp, _, _ := cb.Event.Type()
+				done := observe(ctx, kindEvent, evTyp)
+
+				// the span of the request lasts until the event is handled
+				endSpan := holdSpan(ctx)
+				span := trace.SpanFromContext(RequestContext(ctx))
+
+				// handle it after the response, when ctx is no longer ours
+				evCtx := snapshot(ctx)
+
 				go func() {
-					if err := handler.HandleEvent(ctx, &cb); err != nil {
-						ctx.Logger().Errorf("failed to handle event: %v", err)
+					defer endSpan()
+
+					err := handle(evCtx)
+					done(err)
+
+					if err != nil {
+						span.RecordError(err)
+						evCtx.Logger().Errorf("failed to handle event: %v", err)
 					}
 				}()
 
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const testLinkSharedBody = `{"type":"event_callback","team_id":"T1","event":{"type":"link_shared","channel":"C1","user":"U1","message_ts":"1.0","links":[{"domain":"example.com","url":"https://example.com/12345"}]}}`
+
+type testEventHandler chan string
+
+func (h testEventHandler) HandleEvent(ctx Context, cb *EventCallback) error {
+	h <- "event"
+	return nil
+}
+
+func (h testEventHandler) HandleLinkShared(ctx Context, cb *EventCallback, linkShared *LinkShared) error {
+	h <- linkShared.Links[0].Url
+	return nil
+}
+
+func serveEvent(h handler, body string) int {
+	_, _, handlerFunc, _ := h()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/event", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.Set("reqBody", []byte(body))
+
+	handlerFunc(ctx)
+	return rec.Code
+}
+
+func TestLinkShared(t *testing.T) {
+	h := make(testEventHandler, 1)
+
+	for _, tc := range []struct {
+		opts     []EventOption
+		expected string
+	}{
+		{nil, "event"},
+		{[]EventOption{OnLinkShared(h)}, "https://example.com/12345"},
+	} {
+		if code := serveEvent(EventSubscriptions("/event", h, tc.opts...), testLinkSharedBody); code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", code)
+		}
+
+		select {
+		case got := <-h:
+			if got != tc.expected {
+				t.Errorf("event must be handled by '%s', but '%s'", tc.expected, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("event was not handled")
+		}
+	}
+}
+
+// newSignedRequest makes a request to endpoint signed by testSigningSecret
+func newSignedRequest(endpoint, body string) *http.Request {
+	timestamp := time.Now().Unix()
+
+	hm := hmac.New(sha256.New, []byte(testSigningSecret))
+	fmt.Fprintf(hm, "v0:%d:%s", timestamp, body)
+
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(body))
+	req.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Slack-Signature", fmt.Sprintf("v0=%x", hm.Sum(nil)))
+
+	return req
+}
+
+type releasedEventHandler struct {
+	release chan struct{}
+	bodies  chan string
+}
+
+func (h releasedEventHandler) HandleEvent(ctx Context, cb *EventCallback) error {
+	<-h.release
+
+	// the request has been responded and its echo context may serve another
+	body, _ := ctx.Get("reqBody").([]byte)
+	ctx.Logger().Debugf("handling event of %s", cb.TeamId)
+
+	if RequestContext(ctx) == nil || !strings.Contains(string(body), fmt.Sprintf(`"team_id":"%s"`, cb.TeamId)) {
+		h.bodies <- "mismatch"
+		return nil
+	}
+
+	h.bodies <- string(body)
+	return nil
+}
+
+func TestEventHandledAfterResponse(t *testing.T) {
+	h := releasedEventHandler{release: make(chan struct{}), bodies: make(chan string, 20)}
+	method, path, handlerFunc, _ := EventSubscriptions("/event", h)()
+
+	e := echo.New()
+	e.Add(method, path, handlerFunc, NewVerifier(testSigningSecret).Middleware())
+
+	sent := make(map[string]bool)
+
+	// the second batch reuses echo contexts of the first one
+	for batch := 0; batch < 2; batch++ {
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			body := strings.Replace(testLinkSharedBody, `"team_id":"T1"`, fmt.Sprintf(`"team_id":"T%d-%d"`, batch, i), 1)
+			sent[body] = true
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, newSignedRequest("/event", body))
+
+				if rec.Code != http.StatusOK {
+					t.Errorf("unexpected status: %d", rec.Code)
+				}
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	close(h.release)
+
+	for n := len(sent); n > 0; n-- {
+		select {
+		case body := <-h.bodies:
+			if !sent[body] {
+				t.Errorf("event must be handled with the values of its request: %s", body)
+			}
+
+			delete(sent, body)
+		case <-time.After(time.Second):
+			t.Fatal("event was not handled")
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInstallationNotFound = errors.New("installation not found")
+
+// Installation is the result of installing the app to a workspace (or to an
+// Enterprise Grid organization)
+type Installation struct {
+	AppId               string `json:"app_id"`
+	EnterpriseId        string `json:"enterprise_id,omitempty"`
+	EnterpriseName      string `json:"enterprise_name,omitempty"`
+	TeamId              string `json:"team_id,omitempty"`
+	TeamName            string `json:"team_name,omitempty"`
+	IsEnterpriseInstall bool   `json:"is_enterprise_install"`
+
+	BotUserId         string    `json:"bot_user_id"`
+	BotToken          string    `json:"bot_token"`
+	BotScopes         string    `json:"bot_scopes"`
+	BotRefreshToken   string    `json:"bot_refresh_token,omitempty"`
+	BotTokenExpiresAt time.Time `json:"bot_token_expires_at,omitempty"`
+
+	UserId    string `json:"user_id"`
+	UserToken string `json:"user_token,omitempty"`
+	UserScope string `json:"user_scope,omitempty"`
+
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// InstallationStore persists installations; Find looks for the installation
+// of the workspace and falls back to the org-wide one of the enterprise
+type InstallationStore interface {
+	Save(ctx context.Context, installation *Installation) error
+	Find(ctx context.Context, enterpriseId, teamId string) (*Installation, error)
+	Delete(ctx context.Context, enterpriseId, teamId string) error
+}
+
+func installationKey(enterpriseId, teamId string) string {
+	if teamId == "" {
+		return fmt.Sprintf("E-%s", enterpriseId)
+	}
+
+	return fmt.Sprintf("E-%s-T-%s", enterpriseId, teamId)
+}
+
+func (installation *Installation) key() string {
+	if installation.IsEnterpriseInstall {
+		return installationKey(installation.EnterpriseId, "")
+	}
+
+	return installationKey(installation.EnterpriseId, installation.TeamId)
+}
+
+// candidateKeys returns keys to look up in order
+func candidateKeys(enterpriseId, teamId string) []string {
+	keys := []string{installationKey(enterpriseId, teamId)}
+
+	if enterpriseId != "" && teamId != "" {
+		keys = append(keys, installationKey(enterpriseId, ""))
+	}
+
+	return keys
+}
+
+type MemoryInstallationStore struct {
+	installations map[string]Installation
+	lock          *sync.RWMutex
+}
+
+func NewMemoryInstallationStore() *MemoryInstallationStore {
+	return &MemoryInstallationStore{
+		installations: make(map[string]Installation),
+		lock:          new(sync.RWMutex),
+	}
+}
+
+func (store *MemoryInstallationStore) Save(_ context.Context, installation *Installation) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.installations[installation.key()] = *installation
+	return nil
+}
+
+func (store *MemoryInstallationStore) Find(_ context.Context, enterpriseId, teamId string) (*Installation, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	for _, key := range candidateKeys(enterpriseId, teamId) {
+		if installation, ok := store.installations[key]; ok {
+			return &installation, nil
+		}
+	}
+
+	return nil, ErrInstallationNotFound
+}
+
+func (store *MemoryInstallationStore) Delete(_ context.Context, enterpriseId, teamId string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.installations, installationKey(enterpriseId, teamId))
+	return nil
+}
+
+// FileInstallationStore stores each installation as a JSON file in a
+// directory; files are readable only by the owner since they contain tokens
+type FileInstallationStore struct {
+	dir  string
+	lock *sync.RWMutex
+}
+
+func NewFileInstallationStore(dir string) (*FileInstallationStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to make installation directory '%s': %v", dir, err)
+	}
+
+	return &FileInstallationStore{
+		dir:  dir,
+		lock: new(sync.RWMutex),
+	}, nil
+}
+
+func (store *FileInstallationStore) path(key string) string {
+	// ids consist of alphanumerics; keep the file name safe anyway
+	key = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+
+		return r
+	}, key)
+
+	return filepath.Join(store.dir, key+".json")
+}
+
+func (store *FileInstallationStore) Save(_ context.Context, installation *Installation) error {
+	b, err := json.Marshal(installation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installation: %v", err)
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	// write atomically
+	path := store.path(installation.key())
+
+	tmp, err := ioutil.TempFile(store.dir, ".installation-")
+	if err != nil {
+		return fmt.Errorf("failed to make temp file: %v", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write installation: %v", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write installation: %v", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save installation: %v", err)
+	}
+
+	return nil
+}
+
+func (store *FileInstallationStore) Find(_ context.Context, enterpriseId, teamId string) (*Installation, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	for _, key := range candidateKeys(enterpriseId, teamId) {
+		b, err := ioutil.ReadFile(store.path(key))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read installation: %v", err)
+		}
+
+		var installation Installation
+		if err = json.Unmarshal(b, &installation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal installation: %v", err)
+		}
+
+		return &installation, nil
+	}
+
+	return nil, ErrInstallationNotFound
+}
+
+func (store *FileInstallationStore) Delete(_ context.Context, enterpriseId, teamId string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	err := os.Remove(store.path(installationKey(enterpriseId, teamId)))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete installation: %v", err)
+	}
+
+	return nil
+}
@@ -20,6 +20,11 @@ type Team struct {
 	Domain string `json:"domain"`
 }
 
+type Enterprise struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
 type Channel struct {
 	Id   string `json:"id"`
 	Name string `json:"name"`
@@ -71,14 +76,16 @@ func safeToString(v interface{}) string {
 type View map[string]interface{}
 
 type BlockActions struct {
-	TriggerId   string                 `json:"trigger_id"`
-	ResponseUrl string                 `json:"response_url"`
-	User        User                   `json:"user"`
-	Team        Team                   `json:"team"`
-	Message     map[string]interface{} `json:"message"`
-	View        View                   `json:"view"`
-	Actions     []Action               `json:"actions"`
-	Hash        string                 `json:"hash"`
+	TriggerId           string                 `json:"trigger_id"`
+	ResponseUrl         string                 `json:"response_url"`
+	User                User                   `json:"user"`
+	Team                Team                   `json:"team"`
+	Enterprise          *Enterprise            `json:"enterprise"`
+	IsEnterpriseInstall bool                   `json:"is_enterprise_install"`
+	Message             map[string]interface{} `json:"message"`
+	View                View                   `json:"view"`
+	Actions             []Action               `json:"actions"`
+	Hash                string                 `json:"hash"`
 }
 
 type Message struct {
@@ -275,14 +282,17 @@ func Interactivity(endpoint string, handler InteractivityHandler) handler {
 				}
 
 				// dispatch it
-				if handler.HandleBlockActions != nil {
-					if err := handler.HandleBlockActions(ctx, &blockActions); err != nil {
-						ctx.Logger().Errorf("failed to handle block actions: %v", err)
-						return ctx.JSON(http.StatusInternalServerError, slackError{
-							ResponseType: "ephemeral",
-							Text:         "I can't handle your block actions",
-						})
-					}
+				done := observe(ctx, kindInteractivity, typ)
+
+				err := handler.HandleBlockActions(ctx, &blockActions)
+				done(err)
+
+				if err != nil {
+					ctx.Logger().Errorf("failed to handle block actions: %v", err)
+					return ctx.JSON(http.StatusInternalServerError, slackError{
+						ResponseType: "ephemeral",
+						Text:         "I can't handle your block actions",
+					})
 				}
 
 			case "message_actions":
@@ -296,14 +306,17 @@ func Interactivity(endpoint string, handler InteractivityHandler) handler {
 				}
 
 				// dispatch it
-				if handler.HandleMessageActions != nil {
-					if err := handler.HandleMessageActions(ctx, &messageActions); err != nil {
-						ctx.Logger().Errorf("failed to handle message actions: %v", err)
-						return ctx.JSON(http.StatusInternalServerError, slackError{
-							ResponseType: "ephemeral",
-							Text:         "I can't handle your message actions",
-						})
-					}
+				done := observe(ctx, kindInteractivity, typ)
+
+				err := handler.HandleMessageActions(ctx, &messageActions)
+				done(err)
+
+				if err != nil {
+					ctx.Logger().Errorf("failed to handle message actions: %v", err)
+					return ctx.JSON(http.StatusInternalServerError, slackError{
+						ResponseType: "ephemeral",
+						Text:         "I can't handle your message actions",
+					})
 				}
 
 			case "view_closed":
@@ -317,14 +330,17 @@ func Interactivity(endpoint string, handler InteractivityHandler) handler {
 				}
 
 				// dispatch it
-				if handler.HandleViewClosed != nil {
-					if err := handler.HandleViewClosed(ctx, &viewClosed); err != nil {
-						ctx.Logger().Errorf("failed to handle view closed: %v", err)
-						return ctx.JSON(http.StatusInternalServerError, slackError{
-							ResponseType: "ephemeral",
-							Text:         "I can't handle your view closed",
-						})
-					}
+				done := observe(ctx, kindInteractivity, typ)
+
+				err := handler.HandleViewClosed(ctx, &viewClosed)
+				done(err)
+
+				if err != nil {
+					ctx.Logger().Errorf("failed to handle view closed: %v", err)
+					return ctx.JSON(http.StatusInternalServerError, slackError{
+						ResponseType: "ephemeral",
+						Text:         "I can't handle your view closed",
+					})
 				}
 
 			case "view_submission":
@@ -338,14 +354,17 @@ func Interactivity(endpoint string, handler InteractivityHandler) handler {
 				}
 
 				// dispatch it
-				if handler.HandleViewSubmission != nil {
-					if err := handler.HandleViewSubmission(ctx, &viewSubmission); err != nil {
-						ctx.Logger().Errorf("failed to handle view submission: %v", err)
-						return ctx.JSON(http.StatusInternalServerError, slackError{
-							ResponseType: "ephemeral",
-							Text:         "I can't handle your view submission",
-						})
-					}
+				done := observe(ctx, kindInteractivity, typ)
+
+				err := handler.HandleViewSubmission(ctx, &viewSubmission)
+				done(err)
+
+				if err != nil {
+					ctx.Logger().Errorf("failed to handle view submission: %v", err)
+					return ctx.JSON(http.StatusInternalServerError, slackError{
+						ResponseType: "ephemeral",
+						Text:         "I can't handle your view submission",
+					})
 				}
 
 			default:
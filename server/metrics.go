@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/metrics"
+	"github.com/scryner/util.slack/trace"
+)
+
+const metricsKey = "slackMetrics"
+
+const (
+	kindSlashCommand  = "slash_command"
+	kindEvent         = "event"
+	kindInteractivity = "interactivity"
+)
+
+type serverMetrics struct {
+	requests            *metrics.Counter
+	errors              *metrics.Counter
+	latency             *metrics.Histogram
+	verificationFailure *metrics.Counter
+}
+
+// Metrics records inbound requests to reg: requests, handler errors and
+// latencies per handler kind and type (e.g., the command or the event type),
+// and verification failures
+func Metrics(reg *metrics.Registry) Option {
+	return func(server *Server) error {
+		server.metrics = &serverMetrics{
+			requests:            reg.Counter("slack_requests_total", "Inbound Slack requests.", "kind", "type"),
+			errors:              reg.Counter("slack_handler_errors_total", "Errors returned by handlers.", "kind", "type"),
+			latency:             reg.Histogram("slack_handler_duration_seconds", "Latency of handlers.", nil, "kind", "type"),
+			verificationFailure: reg.Counter("slack_verification_failures_total", "Requests failed to be verified as from Slack."),
+		}
+
+		return nil
+	}
+}
+
+// MetricsHandler serves metrics of reg in the text exposition format (e.g.,
+// for "/metrics")
+func MetricsHandler(endpoint string, reg *metrics.Registry) handler {
+	return Http(http.MethodGet, endpoint, echo.WrapHandler(reg.Handler()))
+}
+
+func (m *serverMetrics) middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set(metricsKey, m)
+			return next(ctx)
+		}
+	}
+}
+
+func (m *serverMetrics) verificationFailed() {
+	if m != nil {
+		m.verificationFailure.Inc()
+	}
+}
+
+// observe counts a request of the kind and type, tags the span of the request
+// with them, and returns the function to be called with the result of the
+// handler
+func observe(ctx Context, kind, typ string) func(err error) {
+	trace.SpanFromContext(RequestContext(ctx)).SetAttributes(
+		trace.String("slack.kind", kind),
+		trace.String("slack.type", typ),
+	)
+
+	m, _ := ctx.Get(metricsKey).(*serverMetrics)
+	if m == nil {
+		return func(error) {}
+	}
+
+	started := time.Now()
+	m.requests.Inc(kind, typ)
+
+	return func(err error) {
+		m.latency.Observe(time.Since(started).Seconds(), kind, typ)
+
+		if err != nil {
+			m.errors.Inc(kind, typ)
+		}
+	}
+}
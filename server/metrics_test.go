@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/block"
+	"github.com/scryner/util.slack/metrics"
+)
+
+type failingCommandHandler struct{}
+
+func (failingCommandHandler) HandleCommand(Context, *SlashCommandRequest) (block.Message, error) {
+	return nil, errors.New("failed")
+}
+
+func TestMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	srv, err := New("secret", Metrics(reg))
+	if err != nil {
+		t.Fatal("failed to make server:", err)
+	}
+
+	// serve a slash command through the metrics middleware
+	body := "command=%2Fdeploy&text=now"
+
+	_, _, handlerFunc, _ := SlashCommand("/cmd", failingCommandHandler{})()
+
+	req := httptest.NewRequest(http.MethodPost, "/cmd", strings.NewReader(body))
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	ctx.Set("reqBody", []byte(body))
+
+	srv.metrics.middleware()(handlerFunc)(ctx)
+
+	// scrape
+	rec := serveGet(MetricsHandler("/metrics", reg), "/metrics")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+
+	exposition := rec.Body.String()
+
+	for _, expected := range []string{
+		`slack_requests_total{kind="slash_command",type="/deploy"} 1`,
+		`slack_handler_errors_total{kind="slash_command",type="/deploy"} 1`,
+		`slack_handler_duration_seconds_count{kind="slash_command",type="/deploy"} 1`,
+	} {
+		if !strings.Contains(exposition, expected) {
+			t.Errorf("'%s' is missing in:\n%s", expected, exposition)
+		}
+	}
+}
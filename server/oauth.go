@@ -0,0 +1,390 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/api"
+)
+
+const (
+	DefaultAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+	stateCookieName = "slack-oauth-state"
+	stateExpiration = 10 * time.Minute
+)
+
+type OAuthConfig struct {
+	ClientId     string
+	ClientSecret string
+	Scopes       []string // bot scopes
+	UserScopes   []string
+	RedirectURI  string // optional if only one redirect URL is configured in the app
+
+	// Store keeps installations; an in-memory store is used if nil
+	Store InstallationStore
+
+	// APIOptions are applied to clients made by Installer.API, and to
+	// oauth.v2.access calls except api.ValidateToken and api.DryRun
+	APIOptions []api.Option
+
+	// SuccessURL and FailureURL are where users are redirected after the
+	// installation; a plain text page is shown if empty
+	SuccessURL string
+	FailureURL string
+
+	// AuthorizeURL defaults to DefaultAuthorizeURL
+	AuthorizeURL string
+}
+
+// Installer implements "Add to Slack" by OAuth v2 and hands out API clients
+// for the installed workspaces
+type Installer struct {
+	config OAuthConfig
+
+	apis map[string]*api.API
+	lock *sync.Mutex
+}
+
+func NewInstaller(config OAuthConfig) (*Installer, error) {
+	if config.ClientId == "" || config.ClientSecret == "" {
+		return nil, errors.New("empty client id or client secret")
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryInstallationStore()
+	}
+
+	if config.AuthorizeURL == "" {
+		config.AuthorizeURL = DefaultAuthorizeURL
+	}
+
+	return &Installer{
+		config: config,
+		apis:   make(map[string]*api.API),
+		lock:   new(sync.Mutex),
+	}, nil
+}
+
+// InstallHandler redirects users to the Slack authorize page (e.g., for
+// "/slack/install")
+func (installer *Installer) InstallHandler(endpoint string) handler {
+	return Http(http.MethodGet, endpoint, func(ctx echo.Context) error {
+		state, err := installer.newState(time.Now())
+		if err != nil {
+			ctx.Logger().Errorf("failed to make oauth state: %v", err)
+			return ctx.String(http.StatusInternalServerError, "failed to start installation")
+		}
+
+		ctx.SetCookie(installer.stateCookie(ctx, state, stateExpiration))
+
+		params := make(url.Values)
+		params.Set("client_id", installer.config.ClientId)
+		params.Set("scope", strings.Join(installer.config.Scopes, ","))
+		params.Set("state", state)
+
+		if len(installer.config.UserScopes) > 0 {
+			params.Set("user_scope", strings.Join(installer.config.UserScopes, ","))
+		}
+
+		if installer.config.RedirectURI != "" {
+			params.Set("redirect_uri", installer.config.RedirectURI)
+		}
+
+		return ctx.Redirect(http.StatusFound, installer.config.AuthorizeURL+"?"+params.Encode())
+	})
+}
+
+// RedirectHandler completes the installation (e.g., for "/slack/oauth_redirect");
+// it verifies the state, exchanges the code for tokens and saves the
+// installation to the store
+func (installer *Installer) RedirectHandler(endpoint string) handler {
+	return Http(http.MethodGet, endpoint, func(ctx echo.Context) error {
+		// verify state against the one issued to this browser
+		state := ctx.QueryParam("state")
+
+		cookie, err := ctx.Cookie(stateCookieName)
+		if err != nil || cookie.Value == "" || !hmac.Equal([]byte(cookie.Value), []byte(state)) {
+			ctx.Logger().Errorf("oauth state mismatched")
+			return installer.fail(ctx, http.StatusBadRequest, "invalid state")
+		}
+
+		// state is for one use only
+		ctx.SetCookie(installer.stateCookie(ctx, "", -1))
+
+		if err = installer.verifyState(state, time.Now()); err != nil {
+			ctx.Logger().Errorf("invalid oauth state: %v", err)
+			return installer.fail(ctx, http.StatusBadRequest, "invalid state")
+		}
+
+		// user may have cancelled it
+		if e := ctx.QueryParam("error"); e != "" {
+			return installer.fail(ctx, http.StatusOK, fmt.Sprintf("installation was cancelled: %s", e))
+		}
+
+		code := ctx.QueryParam("code")
+		if code == "" {
+			return installer.fail(ctx, http.StatusBadRequest, "empty code")
+		}
+
+		reqCtx := ctx.Request().Context()
+
+		access, err := api.ExchangeOAuthCode(reqCtx, installer.config.ClientId, installer.config.ClientSecret,
+			code, installer.config.RedirectURI, installer.config.APIOptions...)
+		if err != nil {
+			ctx.Logger().Errorf("failed to exchange oauth code: %v", err)
+			return installer.fail(ctx, http.StatusBadGateway, "failed to exchange code")
+		}
+
+		installation := newInstallation(access, time.Now())
+
+		if err = installer.config.Store.Save(reqCtx, installation); err != nil {
+			ctx.Logger().Errorf("failed to save installation: %v", err)
+			return installer.fail(ctx, http.StatusInternalServerError, "failed to save installation")
+		}
+
+		// forget the client of the previous installation
+		installer.evict(installation.EnterpriseId, installation.TeamId)
+
+		if installer.config.SuccessURL != "" {
+			return ctx.Redirect(http.StatusFound, installer.config.SuccessURL)
+		}
+
+		return ctx.String(http.StatusOK, "The app was installed successfully")
+	})
+}
+
+func (installer *Installer) fail(ctx echo.Context, status int, reason string) error {
+	if installer.config.FailureURL != "" {
+		return ctx.Redirect(http.StatusFound, installer.config.FailureURL)
+	}
+
+	return ctx.String(status, reason)
+}
+
+func (installer *Installer) stateCookie(ctx echo.Context, state string, maxAge time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     stateCookieName,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(maxAge / time.Second),
+		Secure:   ctx.Scheme() == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// newState returns a nonce with the issued time signed by the client secret
+func (installer *Installer) newState(now time.Time) (string, error) {
+	payload := make([]byte, 24)
+
+	if _, err := rand.Read(payload[:16]); err != nil {
+		return "", err
+	}
+
+	binary.BigEndian.PutUint64(payload[16:], uint64(now.Unix()))
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(installer.sign(payload)), nil
+}
+
+func (installer *Installer) verifyState(state string, now time.Time) error {
+	parts := strings.SplitN(state, ".", 2)
+	if len(parts) != 2 {
+		return errors.New("malformed state")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(payload) != 24 {
+		return errors.New("malformed state")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, installer.sign(payload)) {
+		return errors.New("invalid signature")
+	}
+
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if now.Sub(issuedAt) > stateExpiration {
+		return errors.New("expired state")
+	}
+
+	return nil
+}
+
+func (installer *Installer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(installer.config.ClientSecret))
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
+
+func newInstallation(access *api.OAuthV2Access, now time.Time) *Installation {
+	installation := &Installation{
+		AppId:               access.AppID,
+		TeamId:              access.Team.ID,
+		TeamName:            access.Team.Name,
+		IsEnterpriseInstall: access.IsEnterpriseInstall,
+		BotUserId:           access.BotUserID,
+		BotToken:            access.AccessToken,
+		BotScopes:           access.Scope,
+		BotRefreshToken:     access.RefreshToken,
+		BotTokenExpiresAt:   access.ExpiresAt(now),
+		UserId:              access.AuthedUser.ID,
+		UserToken:           access.AuthedUser.AccessToken,
+		UserScope:           access.AuthedUser.Scope,
+		InstalledAt:         now,
+	}
+
+	if access.Enterprise != nil {
+		installation.EnterpriseId = access.Enterprise.ID
+		installation.EnterpriseName = access.Enterprise.Name
+	}
+
+	return installation
+}
+
+// API returns a client with the bot token installed to the team (or to the
+// enterprise for org-wide installations)
+func (installer *Installer) API(ctx context.Context, enterpriseId, teamId string) (*api.API, error) {
+	key := installationKey(enterpriseId, teamId)
+
+	installer.lock.Lock()
+	slack, ok := installer.apis[key]
+	installer.lock.Unlock()
+
+	if ok {
+		return slack, nil
+	}
+
+	installation, err := installer.config.Store.Find(ctx, enterpriseId, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	if installation.BotToken == "" {
+		return nil, fmt.Errorf("no bot token installed to '%s'", key)
+	}
+
+	// an org-wide installation serves every team of the enterprise by one client
+	installer.lock.Lock()
+	slack, ok = installer.apis[installation.key()]
+	if ok {
+		installer.apis[key] = slack
+	}
+	installer.lock.Unlock()
+
+	if ok {
+		return slack, nil
+	}
+
+	opts := installer.config.APIOptions
+
+	if installation.BotRefreshToken != "" {
+		// token rotation is enabled; keep the store up to date with new tokens
+		src := api.NewRotatingTokenSource(installer.config.ClientId, installer.config.ClientSecret, api.Token{
+			AccessToken:  installation.BotToken,
+			RefreshToken: installation.BotRefreshToken,
+			ExpiresAt:    installation.BotTokenExpiresAt,
+		}, func(ctx context.Context, token api.Token) error {
+			installation.BotToken = token.AccessToken
+			installation.BotRefreshToken = token.RefreshToken
+			installation.BotTokenExpiresAt = token.ExpiresAt
+
+			return installer.config.Store.Save(ctx, installation)
+		}, api.RefreshAPIOptions(installer.config.APIOptions...))
+
+		opts = append(opts[:len(opts):len(opts)], api.BotTokenSource(src))
+	}
+
+	slack, err = api.New(installation.BotToken, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	installer.lock.Lock()
+	defer installer.lock.Unlock()
+
+	// concurrent misses made their own clients; keep the first one so that
+	// only one token source refreshes the installation
+	if cached, ok := installer.apis[installation.key()]; ok {
+		slack = cached
+	} else {
+		installer.apis[installation.key()] = slack
+	}
+
+	installer.apis[key] = slack
+
+	return slack, nil
+}
+
+// APIForEvent returns the client for the workspace the event came from
+func (installer *Installer) APIForEvent(ctx context.Context, cb *EventCallback) (*api.API, error) {
+	enterpriseId, teamId := cb.EnterpriseId, cb.TeamId
+
+	if len(cb.Authorizations) > 0 {
+		auth := cb.Authorizations[0]
+
+		if enterpriseId == "" {
+			enterpriseId = auth.EnterpriseId
+		}
+
+		if auth.IsEnterpriseInstall {
+			teamId = ""
+		}
+	}
+
+	return installer.API(ctx, enterpriseId, teamId)
+}
+
+// APIForBlockActions returns the client for the workspace the actions came from
+func (installer *Installer) APIForBlockActions(ctx context.Context, blockActions *BlockActions) (*api.API, error) {
+	var enterpriseId string
+	if blockActions.Enterprise != nil {
+		enterpriseId = blockActions.Enterprise.Id
+	}
+
+	teamId := blockActions.Team.Id
+	if blockActions.IsEnterpriseInstall {
+		teamId = ""
+	}
+
+	return installer.API(ctx, enterpriseId, teamId)
+}
+
+// Uninstall deletes the installation, e.g., on app_uninstalled or
+// tokens_revoked events
+func (installer *Installer) Uninstall(ctx context.Context, enterpriseId, teamId string) error {
+	installer.evict(enterpriseId, teamId)
+	return installer.config.Store.Delete(ctx, enterpriseId, teamId)
+}
+
+func (installer *Installer) evict(enterpriseId, teamId string) {
+	installer.lock.Lock()
+	defer installer.lock.Unlock()
+
+	delete(installer.apis, installationKey(enterpriseId, teamId))
+
+	if teamId == "" && enterpriseId != "" {
+		// clients of teams were served by the org-wide installation
+		prefix := installationKey(enterpriseId, "") + "-"
+
+		for key := range installer.apis {
+			if strings.HasPrefix(key, prefix) {
+				delete(installer.apis, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/api"
+)
+
+func serveGet(h handler, target string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	_, _, handlerFunc, _ := h()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+
+	handlerFunc(echo.New().NewContext(req, rec))
+	return rec
+}
+
+func TestOAuthInstall(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_client_id"}`))
+			return
+		}
+
+		if r.FormValue("code") != "code1" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_code"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"app_id":"A1","access_token":"xoxb-1","token_type":"bot","scope":"chat:write","bot_user_id":"U0","team":{"id":"T1","name":"team"},"enterprise":null,"is_enterprise_install":false,"authed_user":{"id":"U1"}}`))
+	}))
+	defer ts.Close()
+
+	store := NewMemoryInstallationStore()
+
+	installer, err := NewInstaller(OAuthConfig{
+		ClientId:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"chat:write"},
+		Store:        store,
+		APIOptions:   []api.Option{api.ServerAddress(ts.URL)},
+	})
+	if err != nil {
+		t.Fatal("failed to make installer:", err)
+	}
+
+	// install
+	rec := serveGet(installer.InstallHandler("/slack/install"), "/slack/install")
+	if rec.Code != http.StatusFound {
+		t.Fatalf("install must redirect, but %d", rec.Code)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal("invalid location:", err)
+	}
+
+	state := location.Query().Get("state")
+	cookies := rec.Result().Cookies()
+
+	if len(cookies) != 1 || cookies[0].Value != state {
+		t.Fatalf("state cookie must be set: %v", cookies)
+	}
+
+	// forged state
+	rec = serveGet(installer.RedirectHandler("/slack/oauth_redirect"), "/slack/oauth_redirect?code=code1&state=forged", cookies...)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("forged state must be rejected, but %d", rec.Code)
+	}
+
+	// redirect
+	rec = serveGet(installer.RedirectHandler("/slack/oauth_redirect"), "/slack/oauth_redirect?code=code1&state="+url.QueryEscape(state), cookies...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("redirect must succeed, but %d: %s", rec.Code, rec.Body.String())
+	}
+
+	installation, err := store.Find(context.Background(), "", "T1")
+	if err != nil {
+		t.Fatal("installation must be saved:", err)
+	}
+
+	if installation.BotToken != "xoxb-1" || installation.BotUserId != "U0" {
+		t.Errorf("unexpected installation: %+v", installation)
+	}
+
+	if _, err = installer.APIForEvent(context.Background(), &EventCallback{TeamId: "T1"}); err != nil {
+		t.Error("api must be found:", err)
+	}
+
+	if _, err = installer.API(context.Background(), "", "T2"); err != ErrInstallationNotFound {
+		t.Errorf("unknown team must not be found, but %v", err)
+	}
+}
+
+func TestOAuthInstallValidatingToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/oauth.v2.access":
+			w.Write([]byte(`{"ok":true,"app_id":"A1","access_token":"xoxb-1","token_type":"bot","scope":"chat:write","bot_user_id":"U0","team":{"id":"T1","name":"team"}}`))
+
+		case "/api/auth.test":
+			// only the installed bot token is validated
+			if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-1" {
+				w.Write([]byte(`{"ok":false,"error":"not_authed"}`))
+				return
+			}
+
+			w.Header().Set("X-OAuth-Scopes", "chat:write")
+			w.Write([]byte(`{"ok":true,"team_id":"T1","user_id":"U0"}`))
+
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	store := NewMemoryInstallationStore()
+
+	installer, err := NewInstaller(OAuthConfig{
+		ClientId:     "client",
+		ClientSecret: "secret",
+		Store:        store,
+		APIOptions:   []api.Option{api.ServerAddress(ts.URL), api.ValidateToken("chat:write")},
+	})
+	if err != nil {
+		t.Fatal("failed to make installer:", err)
+	}
+
+	state, err := installer.newState(time.Now())
+	if err != nil {
+		t.Fatal("failed to make state:", err)
+	}
+
+	rec := serveGet(installer.RedirectHandler("/slack/oauth_redirect"), "/slack/oauth_redirect?code=code1&state="+url.QueryEscape(state),
+		&http.Cookie{Name: stateCookieName, Value: state})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("redirect must succeed, but %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if _, err = installer.API(context.Background(), "", "T1"); err != nil {
+		t.Error("validated api must be made:", err)
+	}
+}
+
+func TestOAuthStateExpiration(t *testing.T) {
+	installer, err := NewInstaller(OAuthConfig{ClientId: "client", ClientSecret: "secret"})
+	if err != nil {
+		t.Fatal("failed to make installer:", err)
+	}
+
+	now := time.Now()
+
+	state, err := installer.newState(now)
+	if err != nil {
+		t.Fatal("failed to make state:", err)
+	}
+
+	if err = installer.verifyState(state, now.Add(time.Minute)); err != nil {
+		t.Error("state must be valid:", err)
+	}
+
+	if err = installer.verifyState(state, now.Add(time.Hour)); err == nil {
+		t.Error("state must be expired")
+	}
+}
+
+func TestUninstallWorkspace(t *testing.T) {
+	store := NewMemoryInstallationStore()
+
+	installer, err := NewInstaller(OAuthConfig{ClientId: "client", ClientSecret: "secret", Store: store})
+	if err != nil {
+		t.Fatal("failed to make installer:", err)
+	}
+
+	ctx := context.Background()
+
+	for _, teamId := range []string{"T1", "T2"} {
+		if err = store.Save(ctx, &Installation{TeamId: teamId, BotToken: "xoxb-" + teamId}); err != nil {
+			t.Fatal("failed to save:", err)
+		}
+
+		if _, err = installer.API(ctx, "", teamId); err != nil {
+			t.Fatal("api must be found:", err)
+		}
+	}
+
+	// neither enterprise nor team; no cached client must be dropped
+	installer.evict("", "")
+
+	if len(installer.apis) != 2 {
+		t.Errorf("clients of workspaces must be kept: %v", installer.apis)
+	}
+
+	if err = installer.Uninstall(ctx, "", "T1"); err != nil {
+		t.Fatal("failed to uninstall:", err)
+	}
+
+	if _, ok := installer.apis[installationKey("", "T2")]; !ok || len(installer.apis) != 1 {
+		t.Errorf("only the client of the uninstalled workspace must be dropped: %v", installer.apis)
+	}
+
+	if _, err = installer.API(ctx, "", "T1"); err != ErrInstallationNotFound {
+		t.Errorf("uninstalled workspace must not be found, but %v", err)
+	}
+}
+
+func TestInstallerAPIPerInstallation(t *testing.T) {
+	store := NewMemoryInstallationStore()
+
+	installer, err := NewInstaller(OAuthConfig{ClientId: "client", ClientSecret: "secret", Store: store})
+	if err != nil {
+		t.Fatal("failed to make installer:", err)
+	}
+
+	ctx := context.Background()
+
+	err = store.Save(ctx, &Installation{
+		EnterpriseId:        "E1",
+		IsEnterpriseInstall: true,
+		BotToken:            "xoxe.xoxb-E1",
+		BotRefreshToken:     "xoxe-1-E1",
+		BotTokenExpiresAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal("failed to save:", err)
+	}
+
+	// teams of the enterprise missing the cache at once
+	teamIds := []string{"", "T1", "T2", "T1", "T2", ""}
+	apis := make([]*api.API, len(teamIds))
+
+	var wg sync.WaitGroup
+
+	for i, teamId := range teamIds {
+		wg.Add(1)
+
+		go func(i int, teamId string) {
+			defer wg.Done()
+
+			slack, err := installer.API(ctx, "E1", teamId)
+			if err != nil {
+				t.Error("api must be found:", err)
+			}
+
+			apis[i] = slack
+		}(i, teamId)
+	}
+
+	wg.Wait()
+
+	for i, slack := range apis {
+		if slack != apis[0] {
+			t.Errorf("#%d: teams of the org-wide installation must share one client", i)
+		}
+	}
+
+	installer.evict("E1", "")
+
+	if len(installer.apis) != 0 {
+		t.Errorf("clients of the org-wide installation must be dropped: %v", installer.apis)
+	}
+}
+
+func TestFileInstallationStore(t *testing.T) {
+	store, err := NewFileInstallationStore(t.TempDir())
+	if err != nil {
+		t.Fatal("failed to make store:", err)
+	}
+
+	ctx := context.Background()
+
+	// org-wide installation serves every team of the enterprise
+	err = store.Save(ctx, &Installation{EnterpriseId: "E1", IsEnterpriseInstall: true, BotToken: "xoxb-e"})
+	if err != nil {
+		t.Fatal("failed to save:", err)
+	}
+
+	installation, err := store.Find(ctx, "E1", "T1")
+	if err != nil || installation.BotToken != "xoxb-e" {
+		t.Fatalf("org-wide installation must be found: %v", err)
+	}
+
+	if err = store.Delete(ctx, "E1", ""); err != nil {
+		t.Fatal("failed to delete:", err)
+	}
+
+	if _, err = store.Find(ctx, "E1", "T1"); err != ErrInstallationNotFound {
+		t.Errorf("installation must be deleted, but %v", err)
+	}
+}
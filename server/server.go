@@ -9,6 +9,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
+	"github.com/scryner/util.slack/trace"
 )
 
 type LogLvl uint8
@@ -35,6 +36,8 @@ type Server struct {
 	logLevel      log.Lvl
 	handlers      []handler
 	middlewares   []echo.MiddlewareFunc
+	metrics       *serverMetrics
+	tracer        trace.Tracer
 }
 
 type handler func() (method, path string, handlerFunc echo.HandlerFunc, isForSlack bool)
@@ -112,6 +115,15 @@ func (server *Server) StartServer() <-chan error {
 
 		// make verifier
 		verifier := NewVerifier(server.signingSecret)
+		verifier.metrics = server.metrics
+
+		if server.tracer != nil {
+			verifier.tracer = server.tracer
+		}
+
+		if server.metrics != nil {
+			e.Use(server.metrics.middleware())
+		}
 
 		// register other middlewares
 		if len(server.middlewares) > 1 {
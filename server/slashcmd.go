@@ -67,7 +67,10 @@ func SlashCommand(endpoint string, cmdHandler SlashCommandHandler) handler {
 				UserName:    formVals.Get("user_name"),
 			}
 
+			done := observe(ctx, kindSlashCommand, request.Command)
+
 			msg, err := cmdHandler.HandleCommand(ctx, request)
+			done(err)
 
 			if err != nil {
 				ctx.Logger().Errorf("failed to handle request: %v", err)
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/scryner/util.slack/trace"
+)
+
+const (
+	traceContextKey = "slackTraceContext"
+	spanEndKey      = "slackSpanEnd"
+)
+
+// Tracer makes the server start a span for every request from Slack; the span
+// is handed to handlers by RequestContext
+func Tracer(tracer trace.Tracer) Option {
+	return func(server *Server) error {
+		server.tracer = tracer
+		return nil
+	}
+}
+
+// RequestContext returns the context carrying the span of the request, to be
+// given to XXXContext methods of api so that outbound calls join the trace.
+// It carries values of the context of the HTTP request (e.g., the span of a
+// tracing middleware in front), but is not cancelled with the request since
+// events are handled after the response.
+func RequestContext(ctx Context) context.Context {
+	if reqCtx, ok := ctx.Get(traceContextKey).(context.Context); ok {
+		return reqCtx
+	}
+
+	return context.Background()
+}
+
+// detachedContext carries values of the context without its deadline and
+// cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// holdSpan keeps the span of the request from being ended with the response
+// and returns the function to end it, for handlers going on after the
+// response (e.g., events)
+func holdSpan(ctx Context) func() {
+	end, _ := ctx.Get(spanEndKey).(func())
+	if end == nil {
+		return func() {}
+	}
+
+	ctx.Set(spanEndKey, nil)
+	return end
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/api"
+	"github.com/scryner/util.slack/block"
+	"github.com/scryner/util.slack/trace"
+)
+
+type tracingCommandHandler struct {
+	slack *api.API
+}
+
+func (h tracingCommandHandler) HandleCommand(ctx Context, req *SlashCommandRequest) (block.Message, error) {
+	_, err := h.slack.AuthTestContext(RequestContext(ctx))
+	return block.PlainText{Text: "ok"}, err
+}
+
+func TestTracing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	var spans []*trace.SpanData
+	var lock sync.Mutex
+
+	tracer := trace.NewTracer(func(span *trace.SpanData) {
+		lock.Lock()
+		spans = append(spans, span)
+		lock.Unlock()
+	})
+
+	slack, err := api.New("xoxb-test", api.ServerAddress(ts.URL), api.Tracing(tracer))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	v := &Verifier{
+		signingSecret: testSigningSecret,
+		verifyTimestamp: func(_ int64) bool {
+			return true
+		},
+		tracer: tracer,
+	}
+
+	_, _, handlerFunc, _ := SlashCommand("/cmd", tracingCommandHandler{slack: slack})()
+
+	req := httptest.NewRequest(http.MethodPost, "/cmd", strings.NewReader(testReqBody))
+	req.Header.Set("X-Slack-Request-Timestamp", strconv.Itoa(testReqTimestamp))
+	req.Header.Set("X-Slack-Signature", testReqSignature)
+
+	rec := httptest.NewRecorder()
+
+	if err = v.Middleware()(handlerFunc)(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal("failed to handle:", err)
+	}
+
+	if len(spans) != 2 {
+		t.Fatalf("2 spans must be recorded, but %d", len(spans))
+	}
+
+	// the api call ends first
+	outbound, inbound := spans[0], spans[1]
+
+	if outbound.Name != "slack.api auth.test" || outbound.Parent != inbound.Context {
+		t.Errorf("api call must be a child of the request: %+v, %+v", outbound, inbound)
+	}
+
+	if outbound.Context.TraceID != inbound.Context.TraceID || inbound.Parent.IsValid() {
+		t.Errorf("spans must share a trace: %+v, %+v", outbound, inbound)
+	}
+}
+
+type tracingEventHandler struct {
+	slack   *api.API
+	release chan struct{}
+}
+
+func (h tracingEventHandler) HandleEvent(ctx Context, cb *EventCallback) error {
+	<-h.release
+
+	if _, err := h.slack.AuthTestContext(RequestContext(ctx)); err != nil {
+		return err
+	}
+
+	return errors.New("failed to handle")
+}
+
+func TestTracingEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	exported := make(chan *trace.SpanData, 3)
+	tracer := trace.NewTracer(func(span *trace.SpanData) {
+		exported <- span
+	})
+
+	slack, err := api.New("xoxb-test", api.ServerAddress(ts.URL), api.Tracing(tracer))
+	if err != nil {
+		t.Fatal("failed to make api:", err)
+	}
+
+	v := NewVerifier(testSigningSecret)
+	v.tracer = tracer
+
+	h := tracingEventHandler{slack: slack, release: make(chan struct{})}
+	method, path, handlerFunc, _ := EventSubscriptions("/event", h)()
+
+	// served by echo, which reuses the context once responded
+	e := echo.New()
+	e.Add(method, path, handlerFunc, v.Middleware())
+
+	// the request comes with the span of a tracing middleware in front
+	upstreamCtx, upstream := tracer.Start(context.Background(), "upstream")
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newSignedRequest("/event", testLinkSharedBody).WithContext(upstreamCtx))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", rec.Code)
+	}
+
+	// responded, but the event is still being handled
+	select {
+	case span := <-exported:
+		t.Fatalf("span must not be ended before the event is handled: %+v", span)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(h.release)
+
+	var spans []*trace.SpanData
+	for len(spans) < 2 {
+		select {
+		case span := <-exported:
+			spans = append(spans, span)
+		case <-time.After(time.Second):
+			t.Fatalf("spans must be ended after the event is handled: %d", len(spans))
+		}
+	}
+
+	outbound, inbound := spans[0], spans[1]
+
+	if inbound.Parent != upstream.SpanContext() {
+		t.Errorf("request must be a child of the upstream span: %+v", inbound)
+	}
+
+	if outbound.Parent != inbound.Context {
+		t.Errorf("api call must be a child of the request: %+v, %+v", outbound, inbound)
+	}
+
+	if inbound.Err == nil {
+		t.Errorf("error of the event handler must be recorded: %+v", inbound)
+	}
+}
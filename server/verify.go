@@ -9,12 +9,15 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/scryner/util.slack/trace"
 )
 
 // Verifier verifies whether request was came from Slack
 type Verifier struct {
 	signingSecret   string
 	verifyTimestamp func(int64) bool
+	metrics         *serverMetrics
+	tracer          trace.Tracer
 }
 
 // Verify do verifying request
@@ -53,6 +56,26 @@ func (v *Verifier) Verify(timestamp int64, reqSignature, reqBody string) error {
 func (v *Verifier) Middleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
+			tracer := v.tracer
+			if tracer == nil {
+				tracer = trace.Noop
+			}
+
+			// start the trace of the request, as a child of the span in the
+			// request context if any
+			spanCtx, span := tracer.Start(detachedContext{ctx.Request().Context()}, "slack.inbound "+ctx.Path(),
+				trace.String("http.path", ctx.Request().URL.Path))
+
+			ctx.Set(traceContextKey, spanCtx)
+			ctx.Set(spanEndKey, span.End)
+
+			// the span is ended with the response unless a handler held it
+			defer func() {
+				if end, _ := ctx.Get(spanEndKey).(func()); end != nil {
+					end()
+				}
+			}()
+
 			// verify token
 			reqTimestamp := fromHeaderAsInt64(ctx.Request().Header, "X-Slack-Request-Timestamp")
 			reqSignature := ctx.Request().Header.Get("X-Slack-Signature")
@@ -68,11 +91,18 @@ func (v *Verifier) Middleware() echo.MiddlewareFunc {
 			err = v.Verify(reqTimestamp, reqSignature, string(reqBody))
 			if err != nil {
 				ctx.Logger().Errorf("failed to verify request: %v", err)
+				v.metrics.verificationFailed()
+				span.RecordError(err)
 				return echo.ErrForbidden
 			}
 
 			// verified
-			return next(ctx)
+			err = next(ctx)
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
 		}
 	}
 }
@@ -82,6 +112,7 @@ func NewVerifier(signingSecret string) *Verifier {
 	return &Verifier{
 		signingSecret:   signingSecret,
 		verifyTimestamp: defaultVerifyTimestamp,
+		tracer:          trace.Noop,
 	}
 }
 
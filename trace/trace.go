@@ -0,0 +1,194 @@
+// Package trace is a minimal tracing abstraction shaped after OpenTelemetry
+// (a Tracer starts Spans carried by context.Context), so that an OpenTelemetry
+// tracer can be plugged in by a small adapter while nothing depends on a
+// collector by default.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext identifies a span
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+type Span interface {
+	SpanContext() SpanContext
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+type Tracer interface {
+	// Start starts a span as a child of the span in ctx, if any, and returns
+	// ctx carrying the new span
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns ctx carrying span
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or a no-op span if none
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+
+	return noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SpanContext() SpanContext   { return SpanContext{} }
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// Noop is a tracer which records nothing
+var Noop Tracer = noopTracer{}
+
+// SpanData is a finished span given to the exporter of NewTracer
+type SpanData struct {
+	Name       string
+	Context    SpanContext
+	Parent     SpanContext // invalid for root spans
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Err        error
+}
+
+type tracer struct {
+	export func(*SpanData)
+}
+
+// NewTracer makes a tracer with random ids which gives finished spans to
+// export (e.g., to write them to logs)
+func NewTracer(export func(*SpanData)) Tracer {
+	return &tracer{export: export}
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent := SpanFromContext(ctx).SpanContext()
+
+	data := &SpanData{
+		Name:       name,
+		Parent:     parent,
+		Start:      time.Now(),
+		Attributes: attrs,
+	}
+
+	if parent.IsValid() {
+		data.Context.TraceID = parent.TraceID
+	} else {
+		rand.Read(data.Context.TraceID[:])
+	}
+
+	rand.Read(data.Context.SpanID[:])
+
+	s := &span{
+		tracer: t,
+		data:   data,
+		lock:   new(sync.Mutex),
+	}
+
+	return ContextWithSpan(ctx, s), s
+}
+
+type span struct {
+	tracer *tracer
+	data   *SpanData
+	ended  bool
+	lock   *sync.Mutex
+}
+
+func (s *span) SpanContext() SpanContext {
+	return s.data.Context
+}
+
+func (s *span) SetAttributes(attrs ...Attribute) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+func (s *span) RecordError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.data.Err = err
+}
+
+func (s *span) End() {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.End = time.Now()
+	s.lock.Unlock()
+
+	if s.tracer.export != nil {
+		s.tracer.export(s.data)
+	}
+}
@@ -0,0 +1,37 @@
+package trace
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTracer(t *testing.T) {
+	if SpanFromContext(context.Background()).SpanContext().IsValid() {
+		t.Error("context without span must give an invalid span context")
+	}
+
+	var ended []*SpanData
+
+	tracer := NewTracer(func(span *SpanData) {
+		ended = append(ended, span)
+	})
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child", String("k", "v"))
+
+	child.End()
+	child.End() // ended only once
+	parent.End()
+
+	if len(ended) != 2 {
+		t.Fatalf("2 spans must be ended, but %d", len(ended))
+	}
+
+	if ended[0].Parent != parent.SpanContext() || ended[0].Context.TraceID != parent.SpanContext().TraceID {
+		t.Errorf("child must belong to the parent: %+v", ended[0])
+	}
+
+	if len(ended[0].Attributes) != 1 || ended[0].Attributes[0].Value != "v" {
+		t.Errorf("unexpected attributes: %v", ended[0].Attributes)
+	}
+}